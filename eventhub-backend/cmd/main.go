@@ -6,33 +6,69 @@ import (
 	"eventhub-backend/internal/handlers"
 	"eventhub-backend/internal/middleware"
 	"eventhub-backend/internal/repository"
+	"eventhub-backend/internal/router"
+	"eventhub-backend/internal/search"
 	"eventhub-backend/internal/service"
+	"eventhub-backend/internal/storage"
 	customJwt "eventhub-backend/pkg/jwt"
+	"eventhub-backend/pkg/ticket"
+	"log"
+	"os"
+	_ "time/tzdata"
 
 	"github.com/labstack/echo/v4"
 )
 
 func main() {
-	db := database.ConnectToDB()
-	database.MigrageDB(db)
-
 	cfg := config.Load()
 
-	var jwtManager customJwt.Manager = customJwt.NewJwtManager(cfg.JwtSecretKey)
+	db := database.ConnectToDB()
+	database.MigrageDB(db, cfg.LegacyTimezone)
+
+	jwtManager := newJwtManager(cfg)
+	ticketSigner := newTicketSigner(cfg)
+
+	searcher, err := search.New(cfg.SearchBackend, cfg.ElasticsearchURL, db)
+	if err != nil {
+		log.Fatal("Ошибка при настройке поиска: ", err)
+	}
+
+	blobStore, err := storage.New(cfg.StorageBackend, cfg.StorageLocalDir, cfg.StoragePublicURL, storage.S3Options{
+		Endpoint:  cfg.S3Endpoint,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		Bucket:    cfg.S3Bucket,
+		Region:    cfg.S3Region,
+		UseSSL:    cfg.S3UseSSL,
+	})
+	if err != nil {
+		log.Fatal("Ошибка при настройке хранилища файлов: ", err)
+	}
 
 	// repos
 	userRepo := repository.NewGormUserRepository(db)
 	eventRepo := repository.NewGormEventRepository(db)
 	organizationRepo := repository.NewGormOrganizationRepository(db)
 	notificationRepo := repository.NewGormNotificationRepository(db)
+	sessionRepo := repository.NewGormSessionRepository(db)
+	checkpointRepo := repository.NewGormSearchCheckpointRepository(db)
 
 	// services
-	authService := service.NewAuthService(cfg, *userRepo, jwtManager)
+	authService := service.NewAuthService(cfg, *userRepo, *sessionRepo, jwtManager)
 	registerService := service.NewRegisterService(*userRepo)
 	userService := service.NewUserService(*userRepo)
-	eventService := service.NewEventService(*eventRepo, *organizationRepo)
+	eventService := service.NewEventService(*eventRepo, *organizationRepo, *checkpointRepo, searcher, ticketSigner, blobStore)
 	organizationService := service.NewOrganizationService(*organizationRepo)
-	notificationService := service.NewNotificationService(*notificationRepo, *eventRepo)
+	notificationService := service.NewNotificationService(*notificationRepo, *eventRepo, cfg.RemindMaybe)
+
+	// admin: go run . reindex
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		if err := eventService.UpdateSearchIndex(); err != nil {
+			log.Fatal("Ошибка при переиндексации: ", err)
+		}
+		log.Println("Индексация успешно завершена")
+		return
+	}
 
 	// handlers
 	authHandler := handlers.NewAuthHandler(authService, registerService)
@@ -40,60 +76,64 @@ func main() {
 	userHandler := handlers.NewUserHandler(userService)
 	organizationHandler := handlers.NewOrganizationHandler(organizationService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	jwksHandler := handlers.NewJWKSHandler(jwtManager)
 
 	// middleware
 	authMW := middleware.NewMiddleware(jwtManager)
 
 	e := echo.New()
-	e.POST("/reindex", eventHandler.UpdateSearchIndex) // POST /reindex
-
-	api := e.Group("/api")
-
-	// public
-	api.POST("/refresh", authHandler.Refresh)   // POST /api/refresh
-	api.POST("/register", authHandler.Register) // POST /api/register
-	api.POST("/login", authHandler.Login)       // POST /api/login
-
-	// authorized
-	auth := api.Group("", authMW.AuthRequired)
-
-	// -- events --
-	events := auth.Group("/events")
-	events.GET("", eventHandler.GetAllUser)                       // GET /api/events
-	events.GET("/:id", eventHandler.GetByID)                      // GET /api/events/:id
-	events.GET("/:id/participants", eventHandler.GetParticipants) // GET /api/events/:id/participants
-	events.POST("/:id/join", eventHandler.Join)                   // POST   /api/events/:id/join
-	events.DELETE("/:id/quit", eventHandler.Quit)                 // DELETE /api/events/:id/quit
-	events.DELETE("/:id/delete", eventHandler.Delete)             // DELETE /api/events/:id/delete
-
-	// -- organizations --
-	organizations := auth.Group("/organizations")
-	organizations.GET("", organizationHandler.GetAll)                 // GET /api/organizations
-	organizations.GET("/:id/events", organizationHandler.GetEvents)   // GET /api/organizations/:id/events
-	organizations.GET("/:id", organizationHandler.GetByID)            // GET /api/organizations/:id
-	organizations.GET("/:id/members", organizationHandler.GetMembers) // GET /api/organizations/:id/members
-	organizations.POST("", organizationHandler.Create)                // POST /api/organizations/
-	organizations.POST("/join/:code", organizationHandler.JoinByCode) // POST /api/organizations/join/:code
-
-	// -- organizations (only creator) --
-	orgCreator := organizations.Group("/:id", authMW.IsOrganizationCreator(organizationService))
-	orgCreator.POST("/events", eventHandler.Create)                 // POST /api/organizations/:id/events
-	orgCreator.PUT("/events/:event_id/update", eventHandler.Update) // PUT  /api/organizations/:id/events/:event_id/update
-
-	// -- notifications --
-	notifications := auth.Group("/notifications")
-	notifications.GET("", notificationHandler.GetAll)                  // GET /api/notifications
-	notifications.POST("/:event_id/:type", notificationHandler.Create) // POST /api/notifications/:event_id/:type
-
-	// -- user --
-	users := auth.Group("/users")
-	users.GET("/:id", userHandler.GetByID)         // GET /api/users/:id
-	users.GET("/profile", userHandler.GetUserData) // GET /api/users/profile
+
+	// загруженные файлы при локальном хранилище раздает сам сервер
+	if cfg.StorageBackend == "local" {
+		e.Static("/files", cfg.StorageLocalDir) // GET /files/*
+	}
+
+	router.Register(e, router.Handlers{
+		Auth:         authHandler,
+		Event:        eventHandler,
+		User:         userHandler,
+		Organization: organizationHandler,
+		Notification: notificationHandler,
+		JWKS:         jwksHandler,
+	}, authMW, organizationService)
 
 	// daemons
 	notificationService.StartScheduler()
 	notificationService.StartEventStatusUpdater()
 	eventService.StartIndexUpdater()
+	eventService.StartSeriesExpander()
+	eventService.StartPublisher()
 
 	e.Start(":3000")
 }
+
+func newJwtManager(cfg config.Config) customJwt.Manager {
+	if cfg.JwtAlgorithm == "HS256" {
+		if cfg.JwtSecretKey == "" {
+			log.Fatal("JWT_SECRET_KEY не задан")
+		}
+		return customJwt.NewJwtManager(cfg.JwtSecretKey)
+	}
+
+	keyManager, err := customJwt.LoadKeyManager(cfg.JwtAlgorithm, cfg.JwtKeyID, cfg.JwtPrivateKeyPath, cfg.JwtVerifyKeys)
+	if err != nil {
+		log.Fatal("Ошибка при загрузке ключей JWT: ", err)
+	}
+
+	return keyManager
+}
+
+// newTicketSigner без ключа возвращает nil: сервер работает, но билеты не выдает
+func newTicketSigner(cfg config.Config) *ticket.Signer {
+	if cfg.TicketKeyID == "" || cfg.TicketPrivateKeyPath == "" {
+		log.Println("TICKET_KEY_ID и TICKET_PRIVATE_KEY_PATH не заданы, выдача билетов отключена")
+		return nil
+	}
+
+	signer, err := ticket.LoadSigner(cfg.TicketKeyID, cfg.TicketPrivateKeyPath, cfg.TicketVerifyKeys)
+	if err != nil {
+		log.Fatal("Ошибка при загрузке ключа билетов: ", err)
+	}
+
+	return signer
+}
@@ -3,12 +3,33 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	JwtSecretKey string
+	JwtAlgorithm         string
+	JwtSecretKey         string
+	JwtKeyID             string
+	JwtPrivateKeyPath    string
+	JwtVerifyKeys        map[string]string
+	TicketKeyID          string
+	TicketPrivateKeyPath string
+	TicketVerifyKeys     map[string]string
+	SearchBackend        string
+	ElasticsearchURL     string
+	LegacyTimezone       string
+	RemindMaybe          bool
+	StorageBackend       string
+	StorageLocalDir      string
+	StoragePublicURL     string
+	S3Endpoint           string
+	S3AccessKey          string
+	S3SecretKey          string
+	S3Bucket             string
+	S3Region             string
+	S3UseSSL             bool
 }
 
 func Load() Config {
@@ -17,7 +38,27 @@ func Load() Config {
 		log.Fatal("No .env file found")
 	}
 	return Config{
-		JwtSecretKey: getEnv("JWT_SECRET_KEY", "mysecret"),
+		JwtAlgorithm:         getEnv("JWT_ALGORITHM", "HS256"),
+		JwtSecretKey:         getEnv("JWT_SECRET_KEY", ""),
+		JwtKeyID:             getEnv("JWT_KEY_ID", ""),
+		JwtPrivateKeyPath:    getEnv("JWT_PRIVATE_KEY_PATH", ""),
+		JwtVerifyKeys:        getEnvMap("JWT_VERIFY_KEYS"),
+		TicketKeyID:          getEnv("TICKET_KEY_ID", ""),
+		TicketPrivateKeyPath: getEnv("TICKET_PRIVATE_KEY_PATH", ""),
+		TicketVerifyKeys:     getEnvMap("TICKET_VERIFY_KEYS"),
+		SearchBackend:        getEnv("SEARCH_BACKEND", "elasticsearch"),
+		ElasticsearchURL:     getEnv("ELASTICSEARCH_URL", "http://localhost:9200"),
+		LegacyTimezone:       getEnv("LEGACY_TIMEZONE", "Europe/Moscow"),
+		RemindMaybe:          getEnv("REMIND_MAYBE", "true") == "true",
+		StorageBackend:       getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:      getEnv("STORAGE_LOCAL_DIR", "uploads"),
+		StoragePublicURL:     getEnv("STORAGE_PUBLIC_URL", ""),
+		S3Endpoint:           getEnv("S3_ENDPOINT", "localhost:9000"),
+		S3AccessKey:          getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:          getEnv("S3_SECRET_KEY", ""),
+		S3Bucket:             getEnv("S3_BUCKET", "eventhub"),
+		S3Region:             getEnv("S3_REGION", ""),
+		S3UseSSL:             getEnv("S3_USE_SSL", "false") == "true",
 	}
 }
 
@@ -28,3 +69,18 @@ func getEnv(key string, fallback string) string {
 
 	return fallback
 }
+
+// getEnvMap разбирает значение вида "key1:value1,key2:value2"
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+
+	for _, pair := range strings.Split(getEnv(key, ""), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[k] = v
+	}
+
+	return result
+}
@@ -2,14 +2,87 @@ package database
 
 import (
 	"eventhub-backend/internal/repository"
+	"log"
 
 	"gorm.io/gorm"
 )
 
-func MigrageDB(DB *gorm.DB) {
+// legacyTimezone - пояс, в котором вводились даты и время до перехода на timestamptz
+func MigrageDB(DB *gorm.DB, legacyTimezone string) {
 	DB.AutoMigrate(
 		&repository.EventModel{},
 		&repository.UserModel{},
 		&repository.OrganizationModel{},
+		&repository.OrganizationMemberModel{},
+		&repository.SessionModel{},
+		&repository.EventSearchDocumentModel{},
+		&repository.SearchCheckpointModel{},
+		&repository.EventParticipantModel{},
+		&repository.EventWaitlistModel{},
+		&repository.EventSeriesModel{},
+		&repository.EventSeriesParticipantModel{},
+		&repository.EventTicketTypeModel{},
+		&repository.EventTicketModel{},
+		&repository.EventHostModel{},
+		&repository.EventCommentModel{},
+		&repository.EventPollModel{},
+		&repository.EventPollOptionModel{},
+		&repository.EventPollVoteModel{},
+		&repository.EventFeedbackModel{},
+		&repository.EventAttachmentModel{},
+		&repository.EventFormFieldModel{},
+		&repository.EventStatusChangeModel{},
+		&repository.EventTemplateModel{},
+		&repository.EventRevisionModel{},
 	)
+
+	// мероприятия, созданные до появления updated_at, должны попасть в инкрементальную индексацию
+	if err := DB.Exec("UPDATE events SET updated_at = now() WHERE updated_at IS NULL").Error; err != nil {
+		log.Println("Ошибка при заполнении updated_at:", err)
+	}
+
+	if err := migrateEventTimes(DB, legacyTimezone); err != nil {
+		log.Fatal("Ошибка при переносе времени мероприятий: ", err)
+	}
+}
+
+// migrateEventTimes переводит date, start_time и end_time в starts_at/ends_at.
+// Окончание раньше начала означает, что мероприятие заканчивается на следующий день.
+func migrateEventTimes(DB *gorm.DB, legacyTimezone string) error {
+	migrator := DB.Migrator()
+
+	if migrator.HasColumn(&repository.EventModel{}, "start_time") {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(`UPDATE events SET
+				starts_at = (date + start_time) AT TIME ZONE @tz,
+				ends_at = (date + end_time + CASE WHEN end_time < start_time THEN interval '1 day' ELSE interval '0' END) AT TIME ZONE @tz,
+				timezone = @tz,
+				updated_at = now()
+				WHERE starts_at IS NULL`, map[string]interface{}{"tz": legacyTimezone}).Error; err != nil {
+				return err
+			}
+
+			return tx.Exec("ALTER TABLE events DROP COLUMN date, DROP COLUMN start_time, DROP COLUMN end_time").Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if migrator.HasColumn(&repository.EventSeriesModel{}, "end_time") {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(`UPDATE event_series SET
+				duration = EXTRACT(EPOCH FROM end_time - start_time)::bigint + CASE WHEN end_time < start_time THEN 86400 ELSE 0 END,
+				timezone = @tz`, map[string]interface{}{"tz": legacyTimezone}).Error; err != nil {
+				return err
+			}
+
+			return tx.Exec("ALTER TABLE event_series DROP COLUMN end_time").Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// OpenTest подключается к тестовой базе из TEST_POSTGRES_DSN и пропускает тест,
+// если переменная не задана или база недоступна. Миграции и все изменения теста
+// делаются в одной транзакции, которая откатывается в конце.
+func OpenTest(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN не задан")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Skip("Postgres недоступен:", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil || sqlDB.Ping() != nil {
+		t.Skip("Postgres недоступен")
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+		sqlDB.Close()
+	})
+
+	MigrageDB(tx, "UTC")
+	return tx
+}
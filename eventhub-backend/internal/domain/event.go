@@ -9,20 +9,168 @@ type Event struct {
 	Category    string
 	IsPublic    bool
 	Status      string
-	Date        string
-	StartTime   string
-	EndTime     string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Timezone    string
 	Location    string
 	CreatorId   uint
 }
 
+// CreateEventInput принимает время в RFC 3339 с явным смещением, Timezone -
+// название пояса IANA, в котором проходит мероприятие. RRule - правило
+// повторения по RFC 5545, например FREQ=WEEKLY;BYDAY=TU. Draft и PublishAt
+// учитываются только при создании, дальше статус меняют отдельные действия.
+// TemplateID заполняет незаданные поля из шаблона организации и переносит его
+// анкету, типы билетов и файлы.
 type CreateEventInput struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	Location    string    `json:"location"`
-	IsPublic    bool      `json:"is_public"`
-	Date        time.Time `gorm:"type:date" json:"date"`
-	StartTime   string    `gorm:"type:time" json:"start_time"`
-	EndTime     string    `gorm:"type:time" json:"end_time"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	Location    string     `json:"location"`
+	IsPublic    *bool      `json:"is_public"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Timezone    string     `json:"timezone"`
+	Capacity    *int       `json:"capacity"`
+	RRule       string     `json:"rrule"`
+	Draft       bool       `json:"draft"`
+	PublishAt   *time.Time `json:"publish_at"`
+	TemplateID  *uint      `json:"template_id"`
+}
+
+// Public - без is_public мероприятие закрытое
+func (i CreateEventInput) Public() bool {
+	return i.IsPublic != nil && *i.IsPublic
+}
+
+// DuplicateEventInput - даты копии мероприятия. Без EndsAt сохраняется
+// длительность исходного, без Title - его название.
+type DuplicateEventInput struct {
+	Title     string     `json:"title"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	Draft     bool       `json:"draft"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// TemplateInput сохраняет мероприятие EventID как именованный шаблон организации
+type TemplateInput struct {
+	Name    string `json:"name"`
+	EventID uint   `json:"event_id"`
+}
+
+// Ответы на приглашение; место на мероприятии занимает только RSVPGoing
+const (
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPDeclined = "declined"
+)
+
+// Роль пользователя в мероприятии
+const (
+	EventRoleCreator = "creator"
+	EventRoleHost    = "host"
+)
+
+type RSVPInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// JoinInput - ключи Answers - ID полей анкеты мероприятия
+type JoinInput struct {
+	TicketTypeID *uint                  `json:"ticket_type_id"`
+	Answers      map[string]interface{} `json:"answers"`
+}
+
+type TicketTypeInput struct {
+	Name       string     `json:"name"`
+	Quota      *int       `json:"quota"`
+	SalesStart *time.Time `json:"sales_start"`
+	SalesEnd   *time.Time `json:"sales_end"`
+}
+
+// Типы полей анкеты регистрации
+const (
+	FormFieldText     = "text"
+	FormFieldChoice   = "choice"
+	FormFieldCheckbox = "checkbox"
+	FormFieldNumber   = "number"
+)
+
+// FormFieldInput - поле анкеты. Поле с ID обновляет существующее, чтобы
+// ответы, данные раньше, не потерялись. MaxLength относится к text,
+// Options - к choice, Min и Max - к number.
+type FormFieldInput struct {
+	ID        *uint    `json:"id"`
+	Label     string   `json:"label"`
+	Kind      string   `json:"kind"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options"`
+	MaxLength *int     `json:"max_length"`
+	Min       *float64 `json:"min"`
+	Max       *float64 `json:"max"`
+}
+
+type FormInput struct {
+	Fields []FormFieldInput `json:"fields"`
+}
+
+// Области изменения мероприятия серии
+const (
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+	ScopeSeries    = "series"
+)
+
+// EventSearchParams ищет мероприятия, пересекающиеся с полуинтервалом [From, To)
+type EventSearchParams struct {
+	Query    string
+	Category string
+	From     *time.Time
+	To       *time.Time
+	OrgID    uint
+	Page     int
+	Size     int
+}
+
+type CommentInput struct {
+	Body     string `json:"body"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// Виды опросов
+const (
+	PollSingle   = "single"
+	PollMultiple = "multiple"
+)
+
+// PollOptionInput - вариант ответа. Время и место необязательны: если они заданы,
+// победивший вариант можно применить к мероприятию при закрытии опроса.
+type PollOptionInput struct {
+	Text     string     `json:"text"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Location string     `json:"location"`
+}
+
+type PollInput struct {
+	Question  string            `json:"question"`
+	Kind      string            `json:"kind"`
+	Anonymous bool              `json:"anonymous"`
+	ClosesAt  *time.Time        `json:"closes_at"`
+	Options   []PollOptionInput `json:"options"`
+}
+
+type VoteInput struct {
+	OptionIDs []uint `json:"option_ids"`
+}
+
+type ClosePollInput struct {
+	Apply bool `json:"apply"`
+}
+
+type FeedbackInput struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
 }
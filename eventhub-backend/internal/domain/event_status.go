@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// Статусы мероприятия. До публикации мероприятие видят только те, кто может
+// его редактировать. Жизненный цикл опубликованного мероприятия:
+// active (ждет начала) -> ongoing -> completed, а также cancelled и postponed.
+// Отмененное мероприятие остается видимым с причиной, удаленное - скрыто.
+const (
+	EventStatusDraft           = "draft"
+	EventStatusPendingApproval = "pending_approval"
+	EventStatusScheduled       = "scheduled" // опубликуется в PublishAt
+	EventStatusActive          = "active"
+	EventStatusOngoing         = "ongoing"
+	EventStatusCompleted       = "completed"
+	EventStatusCancelled       = "cancelled"
+	EventStatusPostponed       = "postponed" // новая дата пока не известна
+	EventStatusDeleted         = "deleted"
+)
+
+// eventTransitions - разрешенные переходы между статусами. Статус меняется
+// только через них, обычное редактирование его не трогает.
+var eventTransitions = map[string][]string{
+	EventStatusDraft:           {EventStatusPendingApproval, EventStatusScheduled, EventStatusActive, EventStatusDeleted},
+	EventStatusPendingApproval: {EventStatusDraft, EventStatusScheduled, EventStatusActive, EventStatusDeleted},
+	EventStatusScheduled:       {EventStatusDraft, EventStatusPendingApproval, EventStatusScheduled, EventStatusActive, EventStatusDeleted},
+	EventStatusActive:          {EventStatusOngoing, EventStatusCompleted, EventStatusCancelled, EventStatusPostponed, EventStatusDeleted},
+	EventStatusOngoing:         {EventStatusCompleted, EventStatusCancelled, EventStatusDeleted},
+	EventStatusPostponed:       {EventStatusActive, EventStatusCancelled, EventStatusDeleted},
+	EventStatusCompleted:       {EventStatusDeleted},
+	EventStatusCancelled:       {EventStatusDeleted},
+}
+
+// StatusGroups - порядок групп в списках мероприятий организации
+var StatusGroups = []string{EventStatusActive, EventStatusOngoing, EventStatusPostponed, EventStatusCompleted, EventStatusCancelled}
+
+func CanTransition(from, to string) bool {
+	for _, status := range eventTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnpublishedStatuses - мероприятие еще не опубликовано, его видят только организаторы
+var UnpublishedStatuses = []string{EventStatusDraft, EventStatusPendingApproval, EventStatusScheduled}
+
+func IsUnpublished(status string) bool {
+	for _, s := range UnpublishedStatuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPublished - мероприятие видно участникам, попадает в списки и поиск
+func IsPublished(status string) bool {
+	for _, s := range StatusGroups {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRegistrationOpen - записаться можно, пока мероприятие не закончилось и не отменено
+func IsRegistrationOpen(status string) bool {
+	return status == EventStatusActive || status == EventStatusOngoing || status == EventStatusPostponed
+}
+
+// IsEditable - править можно черновик и мероприятие, которое еще не прошло и не отменено
+func IsEditable(status string) bool {
+	return status == EventStatusDraft || status == EventStatusActive || status == EventStatusPostponed
+}
+
+// PublishInput - без PublishAt (или с прошедшим временем) мероприятие публикуется сразу
+type PublishInput struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// StatusReasonInput - причина отказа в одобрении, отмены или переноса
+type StatusReasonInput struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+type Permission string
+
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
+)
+
+const (
+	PermCreateEvents   Permission = "create_events"
+	PermEditAnyEvent   Permission = "edit_any_event"
+	PermManageMembers  Permission = "manage_members"
+	PermChangeSettings Permission = "change_settings"
+	PermApproveEvents  Permission = "approve_events"
+)
+
+// порядок важен: повышение и понижение двигают участника по этой лестнице
+var RoleLadder = []string{RoleMember, RoleModerator, RoleAdmin, RoleOwner}
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner:     {PermCreateEvents, PermEditAnyEvent, PermManageMembers, PermChangeSettings, PermApproveEvents},
+	RoleAdmin:     {PermCreateEvents, PermEditAnyEvent, PermManageMembers, PermApproveEvents},
+	RoleModerator: {PermCreateEvents, PermEditAnyEvent},
+	RoleMember:    {},
+}
+
+type OrganizationSettingsInput struct {
+	// мероприятия тех, у кого нет права approve_events, публикуются после одобрения
+	RequireApproval bool `json:"require_approval"`
+}
+
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoleRank возвращает 0 для пользователей вне организации
+func RoleRank(role string) int {
+	for i, r := range RoleLadder {
+		if r == role {
+			return i + 1
+		}
+	}
+
+	return 0
+}
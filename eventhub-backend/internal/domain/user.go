@@ -11,3 +11,8 @@ type LoginInput struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+type DeviceInfo struct {
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "errors"
+
+// ValidationError собирает ошибки входных данных по полям, ключ - имя поля в JSON
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed"
+}
+
+// Add запоминает первую ошибку для поля
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	if _, ok := e.Fields[field]; !ok {
+		e.Fields[field] = message
+	}
+}
+
+// Err возвращает nil, если ошибок не было
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidationFields возвращает ошибки по полям, если err - ошибка валидации
+func ValidationFields(err error) (map[string]string, bool) {
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		return validation.Fields, true
+	}
+	return nil, false
+}
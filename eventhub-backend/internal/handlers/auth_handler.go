@@ -30,7 +30,7 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	access, refresh, err := h.authService.RefreshTokens(req.RefreshToken)
+	access, refresh, err := h.authService.RefreshTokens(req.RefreshToken, deviceInfo(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 	}
@@ -65,7 +65,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	accessToken, refreshToken, err := h.authService.Login(input)
+	accessToken, refreshToken, err := h.authService.Login(input, deviceInfo(c))
 	if err != nil {
 		if err.Error() == "invalid credentials" {
 			return echo.NewHTTPError(http.StatusBadRequest, "Неправильный логин или пароль")
@@ -75,3 +75,36 @@ func (h *AuthHandler) Login(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]string{"access_token": accessToken, "refresh_token": refreshToken})
 }
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		if err.Error() == "invalid refresh token" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Токен невалиден")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при выходе из аккаунта")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при выходе со всех устройств")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func deviceInfo(c echo.Context) domain.DeviceInfo {
+	return domain.DeviceInfo{
+		UserAgent: c.Request().UserAgent(),
+		IP:        c.RealIP(),
+	}
+}
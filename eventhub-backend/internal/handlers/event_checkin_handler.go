@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+const ticketQRSize = 512
+
+func (h *EventHandler) Ticket(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	issued, err := h.eventService.Ticket(userID, uint(eventID))
+	if err != nil {
+		if err.Error() == "event not found" {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if err.Error() == "user not joined" {
+			return echo.NewHTTPError(http.StatusForbidden, "Билет выдается только участникам мероприятия")
+		}
+		if err.Error() == "event finished" {
+			return echo.NewHTTPError(http.StatusGone, "Мероприятие уже закончилось")
+		}
+		if err.Error() == "event cancelled" {
+			return echo.NewHTTPError(http.StatusGone, "Мероприятие отменено")
+		}
+		if err.Error() == "tickets disabled" {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Выдача билетов отключена")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при создании билета")
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	// приложение сохраняет билет в JSON, чтобы показывать его без сети
+	if c.QueryParam("format") == "json" {
+		return c.JSON(http.StatusOK, issued)
+	}
+
+	png, err := qrcode.Encode(issued.Token, qrcode.Medium, ticketQRSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при создании билета")
+	}
+
+	return c.Blob(http.StatusOK, "image/png", png)
+}
+
+func (h *EventHandler) CheckIn(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind(&input); err != nil || input.Token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	participant, already, err := h.eventService.CheckIn(userID, uint(eventID), input.Token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав отмечать участников этого мероприятия")
+		}
+		if err.Error() == "invalid ticket" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Билет недействителен")
+		}
+		if err.Error() == "ticket for another event" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Билет выдан на другое мероприятие")
+		}
+		if err.Error() == "ticket revoked" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Билет отозван")
+		}
+		if err.Error() == "ticket expired" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Срок действия билета истек")
+		}
+		if err.Error() == "event cancelled" {
+			return echo.NewHTTPError(http.StatusGone, "Мероприятие отменено")
+		}
+		if err.Error() == "user not joined" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь не записан на это мероприятие")
+		}
+		if err.Error() == "tickets disabled" {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Выдача билетов отключена")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при отметке участника")
+	}
+
+	user, err := h.userService.GetByID(participant.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при отметке участника")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user":               user,
+		"checked_in_at":      participant.CheckedInAt,
+		"already_checked_in": already,
+	})
+}
+
+func (h *EventHandler) Attendance(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	participants, err := h.eventService.Attendance(userID, uint(eventID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к посещаемости этого мероприятия")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении посещаемости")
+	}
+
+	checkedIn := 0
+	attendees := make([]map[string]interface{}, 0, len(participants))
+	for _, participant := range participants {
+		user, err := h.userService.GetByID(participant.UserID)
+		if err != nil {
+			continue
+		}
+
+		if participant.CheckedInAt != nil {
+			checkedIn++
+		}
+		attendees = append(attendees, map[string]interface{}{
+			"user":          user,
+			"status":        participant.Status,
+			"checked_in_at": participant.CheckedInAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total":      len(attendees),
+		"checked_in": checkedIn,
+		"attendees":  attendees,
+	})
+}
+
+func (h *EventHandler) OrganizationAttendance(c echo.Context) error {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	rows, err := h.eventService.OrganizationAttendance(uint(orgID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении посещаемости")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"events": rows})
+}
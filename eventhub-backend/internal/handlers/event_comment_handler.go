@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) GetComments(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var parentID *uint
+	if value := c.QueryParam("parent_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Некорректный параметр parent_id")
+		}
+		parent := uint(id)
+		parentID = &parent
+	}
+
+	var cursor uint64
+	if value := c.QueryParam("cursor"); value != "" {
+		cursor, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Некорректный параметр cursor")
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	page, err := h.eventService.Comments(userID, uint(eventID), parentID, uint(cursor), limit)
+	if err != nil {
+		return commentError(c, err, "Ошибка при получении комментариев")
+	}
+
+	users := make(map[uint]repository.UserResponse)
+	comments := make([]map[string]interface{}, 0, len(page.Comments))
+	for _, row := range page.Comments {
+		comment := h.commentResponse(row.EventCommentModel, users)
+		comment["reply_count"] = row.ReplyCount
+		comments = append(comments, comment)
+	}
+
+	var nextCursor *uint
+	if page.NextCursor != 0 {
+		nextCursor = &page.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"comments":    comments,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *EventHandler) CreateComment(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.CommentInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	comment, err := h.eventService.CreateComment(userID, uint(eventID), input)
+	if err != nil {
+		return commentError(c, err, "Ошибка при создании комментария")
+	}
+
+	return c.JSON(http.StatusCreated, h.commentResponse(comment, map[uint]repository.UserResponse{}))
+}
+
+func (h *EventHandler) UpdateComment(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.CommentInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	comment, err := h.eventService.UpdateComment(userID, uint(eventID), uint(commentID), input)
+	if err != nil {
+		return commentError(c, err, "Ошибка при изменении комментария")
+	}
+
+	return c.JSON(http.StatusOK, h.commentResponse(comment, map[uint]repository.UserResponse{}))
+}
+
+func (h *EventHandler) DeleteComment(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.DeleteComment(userID, uint(eventID), uint(commentID)); err != nil {
+		return commentError(c, err, "Ошибка при удалении комментария")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// commentResponse скрывает текст удаленного комментария; users - кеш авторов в пределах запроса
+func (h *EventHandler) commentResponse(comment repository.EventCommentModel, users map[uint]repository.UserResponse) map[string]interface{} {
+	user, ok := users[comment.UserID]
+	if !ok {
+		user, _ = h.userService.GetByID(comment.UserID)
+		users[comment.UserID] = user
+	}
+
+	response := map[string]interface{}{
+		"id":         comment.ID,
+		"parent_id":  comment.ParentID,
+		"user":       user,
+		"body":       comment.Body,
+		"created_at": comment.CreatedAt,
+		"edited":     comment.EditedAt != nil,
+		"edited_at":  comment.EditedAt,
+		"deleted":    comment.DeletedAt != nil,
+	}
+	if comment.DeletedAt != nil {
+		response["body"] = ""
+		response["user"] = nil
+	}
+
+	return response
+}
+
+func commentError(c echo.Context, err error, fallback string) error {
+	if fields, ok := domain.ValidationFields(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "Проверьте текст комментария",
+			"fields":  fields,
+		})
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав на это действие с комментарием")
+	}
+	if err.Error() == "comment not found" {
+		return echo.NewHTTPError(http.StatusNotFound, "Комментарий не найден")
+	}
+	if err.Error() == "comment deleted" {
+		return echo.NewHTTPError(http.StatusGone, "Комментарий удален")
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) SubmitFeedback(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.FeedbackInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.SubmitFeedback(userID, uint(eventID), input); err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "Проверьте отзыв",
+				"fields":  fields,
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if err.Error() == "event not completed" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Отзыв можно оставить только после окончания мероприятия")
+		}
+		if err.Error() == "user not participant" {
+			return echo.NewHTTPError(http.StatusForbidden, "Отзыв могут оставить только участники мероприятия")
+		}
+		if err.Error() == "feedback already submitted" {
+			return echo.NewHTTPError(http.StatusConflict, "Вы уже оставили отзыв об этом мероприятии")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при сохранении отзыва")
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func (h *EventHandler) GetFeedback(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	summary, feedback, err := h.eventService.Feedback(userID, uint(eventID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "Отзывы доступны только организаторам мероприятия")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении отзывов")
+	}
+
+	reviews := make([]map[string]interface{}, 0, len(feedback))
+	for _, f := range feedback {
+		user, err := h.userService.GetByID(f.UserID)
+		if err != nil {
+			continue
+		}
+		reviews = append(reviews, map[string]interface{}{
+			"user":       user,
+			"rating":     f.Rating,
+			"comment":    f.Comment,
+			"created_at": f.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"summary":  summary,
+		"feedback": reviews,
+	})
+}
+
+func (h *EventHandler) OrganizationFeedback(c echo.Context) error {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	summary, events, err := h.eventService.OrganizationFeedback(uint(orgID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении отзывов")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"summary": summary,
+		"events":  events,
+	})
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) GetForm(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	fields, err := h.eventService.Form(userID, uint(eventID))
+	if err != nil {
+		return formError(err, "Ошибка при получении анкеты")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"fields": fields})
+}
+
+func (h *EventHandler) UpdateForm(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.FormInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	fields, err := h.eventService.UpdateForm(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "Проверьте поля анкеты",
+				"fields":  fields,
+			})
+		}
+		return formError(err, "Ошибка при сохранении анкеты")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"fields": fields})
+}
+
+// GetFormAnswers отдает ответы в JSON или, с ?format=csv, файлом для таблиц
+func (h *EventHandler) GetFormAnswers(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	fields, rows, err := h.eventService.FormAnswers(userID, uint(eventID))
+	if err != nil {
+		return formError(err, "Ошибка при получении ответов")
+	}
+
+	if c.QueryParam("format") == "csv" {
+		header := []string{"ID", "Имя", "Фамилия", "Логин", "Статус"}
+		for _, field := range fields {
+			header = append(header, field.Label)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"event-%d-answers.csv\"", eventID))
+		c.Response().WriteHeader(http.StatusOK)
+
+		writer := csv.NewWriter(c.Response())
+		if err := writer.Write(csvRecord(header)); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			user, err := h.userService.GetByID(row.UserID)
+			if err != nil {
+				continue
+			}
+
+			record := []string{strconv.FormatUint(uint64(row.UserID), 10), user.FirstName, user.LastName, user.UserName, row.Status}
+			for _, field := range fields {
+				record = append(record, formatAnswer(row.Answers[strconv.FormatUint(uint64(field.ID), 10)]))
+			}
+			if err := writer.Write(csvRecord(record)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	answers := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		user, err := h.userService.GetByID(row.UserID)
+		if err != nil {
+			continue
+		}
+		answers = append(answers, map[string]interface{}{
+			"user":    user,
+			"status":  row.Status,
+			"answers": row.Answers,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"fields":  fields,
+		"answers": answers,
+	})
+}
+
+func formatAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "да"
+		}
+		return "нет"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// csvRecord экранирует ячейки, которые табличные редакторы приняли бы за формулу:
+// имена, подписи полей и ответы вводят пользователи. Числа и телефоны со знаком
+// (-5, +7 999 123-45-67) формулой не выполнятся и остаются как есть.
+func csvRecord(record []string) []string {
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		switch value[0] {
+		case '=', '@', '\t', '\r':
+			record[i] = "'" + value
+		case '+', '-':
+			if !isNumeric(value[1:]) {
+				record[i] = "'" + value
+			}
+		}
+	}
+	return record
+}
+
+// isNumeric - число или телефон: цифры, разделенные пробелами, скобками, дефисами и точками
+func isNumeric(value string) bool {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+
+	digits := false
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case !strings.ContainsRune(" ()-.,", r):
+			return false
+		}
+	}
+	return digits
+}
+
+func formError(err error, fallback string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к анкете этого мероприятия")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import "testing"
+
+func TestCSVRecord(t *testing.T) {
+	cells := map[string]string{
+		"":                   "",
+		"Иван":               "Иван",
+		"-5":                 "-5",
+		"+3.14":              "+3.14",
+		"+7 999 123-45-67":   "+7 999 123-45-67",
+		"+7 (999) 123-45-67": "+7 (999) 123-45-67",
+		"=SUM(A1:A2)":        "'=SUM(A1:A2)",
+		"@SUM(A1)":           "'@SUM(A1)",
+		"\tтекст":            "'\tтекст",
+		"\rтекст":            "'\rтекст",
+		"+SUM(A1)":           "'+SUM(A1)",
+		"-cmd|' /C calc'!A0": "'-cmd|' /C calc'!A0",
+		"-":                  "'-",
+		"+ ()":               "'+ ()",
+	}
+
+	for value, want := range cells {
+		if got := csvRecord([]string{value})[0]; got != want {
+			t.Errorf("csvRecord(%q) = %q, ожидалось %q", value, got, want)
+		}
+	}
+}
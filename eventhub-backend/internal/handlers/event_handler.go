@@ -3,11 +3,13 @@ package handlers
 import (
 	"errors"
 	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
 	"eventhub-backend/internal/repository"
 	"eventhub-backend/internal/service"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -38,6 +40,97 @@ func (h *EventHandler) GetAllUser(c echo.Context) error {
 	})
 }
 
+func (h *EventHandler) Search(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	params, err := parseSearchParams(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректные параметры поиска")
+	}
+
+	events, total, err := h.eventService.Search(userID, params)
+	if err != nil {
+		log.Println("Ошибка поиска:", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при поиске мероприятий")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"events": events,
+		"total":  total,
+		"page":   params.Page,
+		"size":   params.Size,
+	})
+}
+
+func parseSearchParams(c echo.Context) (domain.EventSearchParams, error) {
+	params := domain.EventSearchParams{
+		Query:    c.QueryParam("q"),
+		Category: c.QueryParam("category"),
+		Page:     1,
+		Size:     20,
+	}
+
+	if value := c.QueryParam("from"); value != "" {
+		from, err := parseSearchTime(value, false)
+		if err != nil {
+			return params, err
+		}
+		params.From = &from
+	}
+
+	if value := c.QueryParam("to"); value != "" {
+		to, err := parseSearchTime(value, true)
+		if err != nil {
+			return params, err
+		}
+		params.To = &to
+	}
+
+	if value := c.QueryParam("org"); value != "" {
+		orgID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return params, err
+		}
+		params.OrgID = uint(orgID)
+	}
+
+	if value := c.QueryParam("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return params, errors.New("invalid page")
+		}
+		params.Page = page
+	}
+
+	if value := c.QueryParam("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > 50 {
+			return params, errors.New("invalid size")
+		}
+		params.Size = size
+	}
+
+	return params, nil
+}
+
+// parseSearchTime принимает RFC 3339 или дату yyyy-mm-dd в UTC; дата в
+// верхней границе включает весь день
+func parseSearchTime(value string, upper bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+
+	return parsed, nil
+}
+
 func (h *EventHandler) Join(c echo.Context) error {
 	userID := c.Get("userID").(uint)
 
@@ -51,18 +144,76 @@ func (h *EventHandler) Join(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	if err := h.eventService.Join(userID, uint(eventID)); err != nil {
+	if c.QueryParam("scope") == domain.ScopeSeries {
+		return h.joinSeries(c, userID, uint(eventID))
+	}
+
+	var input domain.JoinInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	position, err := h.eventService.Join(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "Проверьте ответы анкеты",
+				"fields":  fields,
+			})
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
 		if err.Error() == "event not found" {
 			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не существует")
 		}
+		if msg, ok := ticketTypeErrors[err.Error()]; ok {
+			return echo.NewHTTPError(http.StatusBadRequest, msg)
+		}
+		if err.Error() == "registration closed" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Запись на это мероприятие закрыта")
+		}
 
 		if err.Error() == "user already joined" {
 			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь уже записан на это мероприятие")
 		}
+		if err.Error() == "user already waitlisted" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь уже в очереди на это мероприятие")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при присоединении к мероприятию")
 	}
 
-	return c.NoContent(http.StatusNoContent)
+	if position > 0 {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":            "waitlisted",
+			"waitlist_position": position,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "joined"})
+}
+
+func (h *EventHandler) joinSeries(c echo.Context, userID, eventID uint) error {
+	if err := h.eventService.JoinSeries(userID, eventID); err != nil {
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
+		if err.Error() == "event not found" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не существует")
+		}
+		if err.Error() == "event is not recurring" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не является повторяющимся")
+		}
+		if err.Error() == "user already joined" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь уже записан на эту серию мероприятий")
+		}
+		if err.Error() == "series registration required" {
+			return echo.NewHTTPError(http.StatusConflict, "Для мероприятий серии нужно выбрать тип билета или заполнить анкету, записывайтесь на каждое отдельно")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при присоединении к мероприятию")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "subscribed"})
 }
 
 func (h *EventHandler) Quit(c echo.Context) error {
@@ -74,12 +225,20 @@ func (h *EventHandler) Quit(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	if err := h.eventService.Quit(userID, uint(eventID)); err != nil {
+	quit := h.eventService.Quit
+	if c.QueryParam("scope") == domain.ScopeSeries {
+		quit = h.eventService.QuitSeries
+	}
+
+	if err := quit(userID, uint(eventID)); err != nil {
 		if err.Error() == "event not found" {
 			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не существует")
 		}
+		if err.Error() == "event is not recurring" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не является повторяющимся")
+		}
 
-		if err.Error() == "not joined" {
+		if err.Error() == "user not joined" {
 			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь не записан на это мероприятие")
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при отмене записи на мероприятие")
@@ -88,6 +247,55 @@ func (h *EventHandler) Quit(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *EventHandler) SetRSVP(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.RSVPInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	position, err := h.eventService.SetRSVP(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "Некорректный ответ",
+				"fields":  fields,
+			})
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
+		if err.Error() == "event not found" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Мероприятие не существует")
+		}
+		if err.Error() == "ticket type required" {
+			return echo.NewHTTPError(http.StatusBadRequest, ticketTypeErrors[err.Error()])
+		}
+		if err.Error() == "registration closed" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Запись на это мероприятие закрыта")
+		}
+		if err.Error() == "registration form required" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Чтобы записаться, заполните анкету мероприятия")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при сохранении ответа")
+	}
+
+	if position > 0 {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":            "waitlisted",
+			"waitlist_position": position,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": input.Status})
+}
+
 func (h *EventHandler) Create(c echo.Context) error {
 	userID := c.Get("userID").(uint)
 
@@ -104,6 +312,12 @@ func (h *EventHandler) Create(c echo.Context) error {
 	}
 
 	if err := h.eventService.Create(input, userID, uint(orgID)); err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		if err.Error() == "template not found" {
+			return echo.NewHTTPError(http.StatusNotFound, "Шаблон не найден")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при создании мероприятия")
 	}
 
@@ -118,11 +332,14 @@ func (h *EventHandler) GetByID(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	event, isCreator, err := h.eventService.GetByID(uint(eventID), userID)
+	event, role, err := h.eventService.GetByID(uint(eventID), userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
 		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении данных мероприятия")
 	}
 
@@ -131,10 +348,41 @@ func (h *EventHandler) GetByID(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при проверке участия в мероприятии")
 	}
 
+	freeSeats, position, err := h.eventService.GetAvailability(userID, uint(eventID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении данных мероприятия")
+	}
+
+	var seatsLeft *int
+	if freeSeats >= 0 {
+		seatsLeft = &freeSeats
+	}
+
+	isSeriesJoined, err := h.eventService.IsUserSubscribed(userID, uint(eventID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при проверке участия в мероприятии")
+	}
+
+	rsvp, err := h.eventService.GetRSVP(userID, uint(eventID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при проверке участия в мероприятии")
+	}
+
+	feedbackSubmitted, err := h.eventService.HasFeedback(userID, uint(eventID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении данных мероприятия")
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"event":      event,
-		"is_creator": isCreator,
-		"is_joined":  isJoined,
+		"event":              event,
+		"is_host":            role != "",
+		"role":               role,
+		"is_joined":          isJoined,
+		"is_series_joined":   isSeriesJoined,
+		"rsvp":               rsvp,
+		"feedback_submitted": feedbackSubmitted,
+		"seats_left":         seatsLeft,
+		"waitlist_position":  position,
 	})
 }
 
@@ -146,18 +394,35 @@ func (h *EventHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	participants, err := h.eventService.GetParticipantIDs(uint(eventID))
+	scope, err := parseScope(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректная область изменения")
 	}
 
-	for _, participant := range participants {
-		if err := h.notificationService.Create(participant, uint(eventID), "cancel"); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
-		}
+	canEdit, err := h.eventService.CanEdit(userID, uint(eventID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при удалении мероприятия")
+	}
+	if !canEdit {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав для удаления этого мероприятия")
 	}
 
-	if err := h.eventService.Delete(userID, uint(eventID)); err != nil {
+	occurrenceIDs, err := h.eventService.OccurrenceIDs(uint(eventID), scope)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при удалении мероприятия")
+	}
+
+	if err := h.notifyParticipants(occurrenceIDs, "cancel"); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
+	}
+
+	if err := h.eventService.Delete(userID, uint(eventID), scope); err != nil {
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав для удаления этого мероприятия")
+		}
+		if err.Error() == "invalid status transition" {
+			return echo.NewHTTPError(http.StatusConflict, "Мероприятие уже удалено")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при удалении мероприятия")
 	}
 
@@ -185,54 +450,120 @@ func (h *EventHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	if err := h.eventService.Update(userID, uint(eventID), uint(orgID), input); err != nil {
+	scope, err := parseScope(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректная область изменения")
+	}
+
+	// участников уведомляет репозиторий вместе с записью правки и только
+	// если изменились время или место
+	if err := h.eventService.Update(userID, uint(eventID), uint(orgID), input, scope); err != nil {
 		if err.Error() == "access denied" {
 			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав для обновления этого мероприятия")
 		}
-		if err.Error() == "event not exists" {
+		if err.Error() == "event not exists" || err.Error() == "event not exists in this organization" {
 			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
 		}
+		if err.Error() == "event not editable" {
+			return echo.NewHTTPError(http.StatusConflict, "Мероприятие в текущем статусе нельзя изменить")
+		}
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
 
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
 	}
 
-	participants, err := h.eventService.GetParticipantIDs(uint(eventID))
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
-	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Мероприятие успешно обновлено"})
+}
 
-	for _, participant := range participants {
-		if err := h.notificationService.Create(participant, uint(eventID), "reschedule"); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при обновлении мероприятия")
-		}
-	}
+func validationError(c echo.Context, fields map[string]string) error {
+	return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"message": "Проверьте данные мероприятия",
+		"fields":  fields,
+	})
+}
 
-	return c.JSON(http.StatusOK, map[string]string{"message": "Мероприятие успешно обновлено"})
+// parseScope читает область изменения вхождения серии: this (по умолчанию) или following
+func parseScope(c echo.Context) (string, error) {
+	switch scope := c.QueryParam("scope"); scope {
+	case "", domain.ScopeThis:
+		return domain.ScopeThis, nil
+	case domain.ScopeFollowing:
+		return scope, nil
+	default:
+		return "", errors.New("invalid scope")
+	}
 }
 
-func (h *EventHandler) UpdateSearchIndex(c echo.Context) error {
-	err := h.eventService.UpdateSearchIndex()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при индексации")
+func (h *EventHandler) notifyParticipants(eventIDs []uint, notificationType string) error {
+	for _, eventID := range eventIDs {
+		participants, err := h.eventService.GetParticipantIDs(eventID)
+		if err != nil {
+			return err
+		}
+
+		for _, participant := range participants {
+			if err := h.notificationService.Create(participant, eventID, notificationType); err != nil {
+				return err
+			}
+		}
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"success": "Индексация успешно завершена"})
+	return nil
 }
 
 func (h *EventHandler) GetParticipants(c echo.Context) error {
+	userID := c.Get("userID").(uint)
 	eventIDstr := c.Param("id")
 	eventID, err := strconv.ParseUint(eventIDstr, 10, 64)
 	if err != nil || eventIDstr == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный ID мероприятия")
 	}
 
-	participantIDs, err := h.eventService.GetParticipantIDs(uint(eventID))
+	participants, err := h.eventService.ListParticipants(userID, uint(eventID))
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении участников мероприятия")
 	}
 
+	result := map[string]interface{}{
+		"participants": h.users(participants.Going),
+		"counts":       participants.Counts,
+	}
+
+	// ответы с заметками и очередь видят только организаторы
+	if participants.Responses != nil {
+		responses := make([]map[string]interface{}, 0, len(participants.Responses))
+		for _, response := range participants.Responses {
+			user, err := h.userService.GetByID(response.UserID)
+			if err != nil {
+				continue
+			}
+
+			responses = append(responses, map[string]interface{}{
+				"user":         user,
+				"status":       response.Status,
+				"note":         response.Note,
+				"responded_at": response.RespondedAt,
+			})
+		}
+
+		result["responses"] = responses
+		result["waitlist"] = h.users(participants.Waitlist)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *EventHandler) users(userIDs []uint) []repository.UserResponse {
 	var users []repository.UserResponse
-	for _, participantID := range participantIDs {
+	for _, participantID := range userIDs {
 		user, err := h.userService.GetByID(participantID)
 		if err != nil {
 			continue
@@ -241,5 +572,5 @@ func (h *EventHandler) GetParticipants(c echo.Context) error {
 		users = append(users, user)
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"participants": users})
+	return users
 }
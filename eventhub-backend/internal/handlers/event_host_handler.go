@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) GetHosts(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	creatorID, hosts, err := h.eventService.Hosts(userID, uint(eventID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении организаторов")
+	}
+
+	result := make([]map[string]interface{}, 0, len(hosts)+1)
+	if creator, err := h.userService.GetByID(creatorID); err == nil {
+		result = append(result, map[string]interface{}{"user": creator, "role": domain.EventRoleCreator})
+	}
+	for _, host := range hosts {
+		user, err := h.userService.GetByID(host.UserID)
+		if err != nil {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"user":     user,
+			"role":     domain.EventRoleHost,
+			"added_by": host.AddedBy,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"hosts": result})
+}
+
+func (h *EventHandler) AddHost(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input struct {
+		UserID uint `json:"user_id"`
+	}
+	if err := c.Bind(&input); err != nil || input.UserID == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.AddHost(userID, uint(eventID), input.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "Назначать организаторов могут создатель мероприятия и администраторы организации")
+		}
+		if err.Error() == "user is creator" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь уже является создателем мероприятия")
+		}
+		if err.Error() == "user not in organization" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Организатором можно назначить только участника организации")
+		}
+		if err.Error() == "user already host" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Пользователь уже является организатором")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при назначении организатора")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *EventHandler) RemoveHost(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	hostID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.RemoveHost(userID, uint(eventID), uint(hostID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "Снимать организаторов могут создатель мероприятия и администраторы организации")
+		}
+		if err.Error() == "user not host" {
+			return echo.NewHTTPError(http.StatusNotFound, "Пользователь не является организатором")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при снятии организатора")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type uploadFunc func(userID, eventID uint, name string, size int64, body io.Reader) (repository.EventAttachmentModel, error)
+
+func (h *EventHandler) UploadCover(c echo.Context) error {
+	return h.upload(c, h.eventService.UploadCover)
+}
+
+func (h *EventHandler) UploadAttachment(c echo.Context) error {
+	return h.upload(c, h.eventService.UploadAttachment)
+}
+
+// upload принимает файл из multipart-поля file
+func (h *EventHandler) upload(c echo.Context, save uploadFunc) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Файл не передан")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+	defer file.Close()
+
+	attachment, err := save(userID, uint(eventID), fileHeader.Filename, fileHeader.Size, file)
+	if err != nil {
+		return mediaError(err, "Ошибка при загрузке файла")
+	}
+
+	return c.JSON(http.StatusCreated, attachment)
+}
+
+func (h *EventHandler) DeleteCover(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.DeleteCover(userID, uint(eventID)); err != nil {
+		return mediaError(err, "Ошибка при удалении обложки")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *EventHandler) DeleteAttachment(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.DeleteAttachment(userID, uint(eventID), uint(attachmentID)); err != nil {
+		return mediaError(err, "Ошибка при удалении файла")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func mediaError(err error, fallback string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав изменять это мероприятие")
+	}
+
+	switch err.Error() {
+	case "attachment not found":
+		return echo.NewHTTPError(http.StatusNotFound, "Файл не найден")
+	case "file too large":
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Файл слишком большой")
+	case "unsupported file type":
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Можно загрузить только картинку (JPEG, PNG, GIF, WebP) или PDF")
+	case "invalid image":
+		return echo.NewHTTPError(http.StatusBadRequest, "Не удалось прочитать картинку")
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) GetPolls(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	polls, err := h.eventService.Polls(userID, uint(eventID))
+	if err != nil {
+		return pollError(c, err, "Ошибка при получении опросов")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"polls": polls})
+}
+
+func (h *EventHandler) CreatePoll(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.PollInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	poll, err := h.eventService.CreatePoll(userID, uint(eventID), input)
+	if err != nil {
+		return pollError(c, err, "Ошибка при создании опроса")
+	}
+
+	return c.JSON(http.StatusCreated, poll)
+}
+
+// GetPoll отдает текущие результаты опроса
+func (h *EventHandler) GetPoll(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, pollID, err := pollParams(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	poll, err := h.eventService.Poll(userID, eventID, pollID)
+	if err != nil {
+		return pollError(c, err, "Ошибка при получении опроса")
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, poll)
+}
+
+func (h *EventHandler) Vote(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, pollID, err := pollParams(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.VoteInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	poll, err := h.eventService.Vote(userID, eventID, pollID, input)
+	if err != nil {
+		return pollError(c, err, "Ошибка при голосовании")
+	}
+
+	return c.JSON(http.StatusOK, poll)
+}
+
+func (h *EventHandler) ClosePoll(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, pollID, err := pollParams(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.ClosePollInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	poll, applied, err := h.eventService.ClosePoll(userID, eventID, pollID, input.Apply)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return pollError(c, err, "Ошибка при закрытии опроса")
+	}
+
+	// о переносе по итогам опроса участников уведомила правка мероприятия
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"poll":    poll,
+		"applied": applied,
+	})
+}
+
+func pollParams(c echo.Context) (uint, uint, error) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pollID, err := strconv.ParseUint(c.Param("poll_id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint(eventID), uint(pollID), nil
+}
+
+func pollError(c echo.Context, err error, fallback string) error {
+	if fields, ok := domain.ValidationFields(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "Проверьте данные опроса",
+			"fields":  fields,
+		})
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "event not exists" {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав на это действие с опросом")
+	}
+
+	switch err.Error() {
+	case "poll not found":
+		return echo.NewHTTPError(http.StatusNotFound, "Опрос не найден")
+	case "poll closed":
+		return echo.NewHTTPError(http.StatusConflict, "Опрос уже закрыт")
+	case "user not participant":
+		return echo.NewHTTPError(http.StatusForbidden, "Голосовать могут только участники мероприятия")
+	case "poll has no winner":
+		return echo.NewHTTPError(http.StatusConflict, "В опросе нет однозначного победителя")
+	case "event not editable":
+		return echo.NewHTTPError(http.StatusConflict, "Мероприятие в текущем статусе нельзя изменить")
+	case "poll option has nothing to apply":
+		return echo.NewHTTPError(http.StatusBadRequest, "В победившем варианте нет времени или места")
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (h *EventHandler) Publish(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.PublishInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	status, err := h.eventService.Publish(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return statusError(err, "Ошибка при публикации мероприятия")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": status})
+}
+
+func (h *EventHandler) Unpublish(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.Unpublish(userID, uint(eventID)); err != nil {
+		return statusError(err, "Ошибка при снятии мероприятия с публикации")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": domain.EventStatusDraft})
+}
+
+func (h *EventHandler) Approve(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	status, err := h.eventService.Approve(userID, uint(eventID))
+	if err != nil {
+		return statusError(err, "Ошибка при одобрении мероприятия")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": status})
+}
+
+func (h *EventHandler) Reject(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.StatusReasonInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.Reject(userID, uint(eventID), input); err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return statusError(err, "Ошибка при отклонении мероприятия")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": domain.EventStatusDraft})
+}
+
+func (h *EventHandler) Cancel(c echo.Context) error {
+	return h.changeStatus(c, h.eventService.Cancel, domain.EventStatusCancelled, "cancel", "Ошибка при отмене мероприятия")
+}
+
+func (h *EventHandler) Postpone(c echo.Context) error {
+	return h.changeStatus(c, h.eventService.Postpone, domain.EventStatusPostponed, "postpone", "Ошибка при переносе мероприятия")
+}
+
+// changeStatus меняет статус с причиной и уведомляет участников
+func (h *EventHandler) changeStatus(c echo.Context, change func(userID, eventID uint, input domain.StatusReasonInput) error, status, notificationType, fallback string) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.StatusReasonInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := change(userID, uint(eventID), input); err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return statusError(err, fallback)
+	}
+
+	if err := h.notifyParticipants([]uint{uint(eventID)}, notificationType); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при отправке уведомлений участникам")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": status})
+}
+
+func (h *EventHandler) Resume(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.Resume(userID, uint(eventID)); err != nil {
+		if err.Error() == "event date not set" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Сначала укажите новую дату мероприятия")
+		}
+		return statusError(err, "Ошибка при возобновлении мероприятия")
+	}
+
+	// возобновление не меняет ни время, ни место: о новой дате участников
+	// уже уведомила правка мероприятия вместе с ее записью в истории
+
+	return c.JSON(http.StatusOK, map[string]string{"status": domain.EventStatusActive})
+}
+
+func (h *EventHandler) StatusHistory(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	changes, err := h.eventService.StatusHistory(userID, uint(eventID))
+	if err != nil {
+		return statusError(err, "Ошибка при получении истории статусов")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"changes": changes})
+}
+
+func (h *EventHandler) History(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	revisions, err := h.eventService.History(userID, uint(eventID))
+	if err != nil {
+		return statusError(err, "Ошибка при получении истории изменений")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"revisions": revisions})
+}
+
+func statusError(err error, fallback string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав менять статус этого мероприятия")
+	}
+	if err.Error() == "invalid status transition" {
+		return echo.NewHTTPError(http.StatusConflict, "Действие недоступно в текущем статусе мероприятия")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Duplicate создает копию мероприятия с новыми датами и возвращает ее
+func (h *EventHandler) Duplicate(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.DuplicateEventInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	event, err := h.eventService.Duplicate(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return templateError(err, "Ошибка при копировании мероприятия")
+	}
+
+	return c.JSON(http.StatusCreated, event)
+}
+
+func (h *EventHandler) GetTemplates(c echo.Context) error {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	templates, err := h.eventService.Templates(uint(orgID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении шаблонов")
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+func (h *EventHandler) CreateTemplate(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.TemplateInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	template, err := h.eventService.SaveTemplate(userID, uint(orgID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return validationError(c, fields)
+		}
+		return templateError(err, "Ошибка при сохранении шаблона")
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+func (h *EventHandler) DeleteTemplate(c echo.Context) error {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("template_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.DeleteTemplate(uint(orgID), uint(templateID)); err != nil {
+		return templateError(err, "Ошибка при удалении шаблона")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func templateError(err error, fallback string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	}
+	if policy.IsForbidden(err) {
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав копировать это мероприятие")
+	}
+
+	switch err.Error() {
+	case "event not found":
+		return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+	case "template not found":
+		return echo.NewHTTPError(http.StatusNotFound, "Шаблон не найден")
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
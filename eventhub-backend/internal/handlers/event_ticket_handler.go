@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ticketTypeErrors - ошибки выбора типа билета при записи на мероприятие
+var ticketTypeErrors = map[string]string{
+	"ticket type required":  "Выберите тип билета",
+	"ticket type not found": "Тип билета не найден",
+	"ticket type sold out":  "Билеты этого типа закончились",
+	"ticket sales closed":   "Продажа билетов этого типа закрыта",
+}
+
+func (h *EventHandler) GetTicketTypes(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	ticketTypes, err := h.eventService.GetTicketTypes(userID, uint(eventID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет доступа к этому мероприятию")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении типов билетов")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"ticket_types": ticketTypes})
+}
+
+func (h *EventHandler) CreateTicketType(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.TicketTypeInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	ticketType, err := h.eventService.CreateTicketType(userID, uint(eventID), input)
+	if err != nil {
+		if fields, ok := domain.ValidationFields(err); ok {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "Проверьте данные типа билета",
+				"fields":  fields,
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав изменять это мероприятие")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при создании типа билета")
+	}
+
+	return c.JSON(http.StatusCreated, ticketType)
+}
+
+func (h *EventHandler) DeleteTicketType(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	ticketTypeID, err := strconv.ParseUint(c.Param("type_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.eventService.DeleteTicketType(userID, uint(eventID), uint(ticketTypeID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав изменять это мероприятие")
+		}
+		if err.Error() == "ticket type not found" {
+			return echo.NewHTTPError(http.StatusNotFound, "Тип билета не найден")
+		}
+		if err.Error() == "ticket type in use" {
+			return echo.NewHTTPError(http.StatusConflict, "По этому типу уже выданы билеты")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при удалении типа билета")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokedTickets отдает сканерам отозванные билеты, отмененные мероприятия и
+// текущий срок действия билетов; since - время прошлой синхронизации
+func (h *EventHandler) RevokedTickets(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var since time.Time
+	if value := c.QueryParam("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Некорректный параметр since")
+		}
+	}
+
+	// время берется до запроса, чтобы не пропустить отзывы во время синхронизации
+	syncedAt := time.Now().UTC()
+
+	sync, err := h.eventService.RevokedTickets(userID, uint(eventID), since)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Мероприятие не найдено")
+		}
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав отмечать участников этого мероприятия")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении отозванных билетов")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revoked":        sync.TicketIDs,
+		"revoked_events": sync.EventIDs,
+		"expires_at":     sync.ExpiresAt,
+		"synced_at":      syncedAt,
+	})
+}
+
+func (h *EventHandler) TicketKeys(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.eventService.TicketKeys())
+}
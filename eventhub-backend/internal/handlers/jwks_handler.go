@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	customJwt "eventhub-backend/pkg/jwt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JWKSHandler struct {
+	jwtManager customJwt.Manager
+}
+
+func NewJWKSHandler(jwtManager customJwt.Manager) *JWKSHandler {
+	return &JWKSHandler{jwtManager: jwtManager}
+}
+
+func (h *JWKSHandler) GetKeys(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
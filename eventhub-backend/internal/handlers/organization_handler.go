@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
 	"eventhub-backend/internal/service"
 	"net/http"
 	"strconv"
@@ -84,21 +86,21 @@ func (h *OrganizationHandler) JoinByCode(c echo.Context) error {
 }
 
 func (h *OrganizationHandler) GetEvents(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
 	orgIDstr := c.Param("id")
 	orgID, err := strconv.ParseUint(orgIDstr, 10, 16)
 	if orgIDstr == "" || err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	activeEvents, completedEvents, err := h.organizationService.GetEvents(uint(orgID))
+	groups, err := h.organizationService.GetEvents(uint(orgID), userID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении мероприятий организации")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"active":    activeEvents,
-		"completed": completedEvents,
-	})
+	// ключи - статусы: active, ongoing, postponed, completed, cancelled и unpublished
+	return c.JSON(http.StatusOK, groups)
 }
 
 func (h *OrganizationHandler) GetByID(c echo.Context) error {
@@ -112,6 +114,14 @@ func (h *OrganizationHandler) GetByID(c echo.Context) error {
 
 	organization, isCreator, err := h.organizationService.GetByID(uint(orgID), userID)
 
+	if err != nil {
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "Вы не состоите в этой организации")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении информации об организации")
+	}
+
+	role, err := h.organizationService.GetRole(uint(orgID), userID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении информации об организации")
 	}
@@ -119,18 +129,24 @@ func (h *OrganizationHandler) GetByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"organization": organization,
 		"is_creator":   isCreator,
+		"role":         role,
 	})
 }
 
 func (h *OrganizationHandler) GetMembers(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
 	orgIDstr := c.Param("id")
 	orgID, err := strconv.ParseUint(orgIDstr, 10, 16)
 	if orgIDstr == "" || err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
 	}
 
-	members, err := h.organizationService.GetMembers(uint(orgID))
+	members, err := h.organizationService.GetMembers(uint(orgID), userID)
 	if err != nil {
+		if policy.IsForbidden(err) {
+			return echo.NewHTTPError(http.StatusForbidden, "Вы не состоите в этой организации")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при получении списка участников")
 	}
 
@@ -138,3 +154,62 @@ func (h *OrganizationHandler) GetMembers(c echo.Context) error {
 		"members": members,
 	})
 }
+
+func (h *OrganizationHandler) UpdateSettings(c echo.Context) error {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	var input domain.OrganizationSettingsInput
+	if err := c.Bind(&input); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	if err := h.organizationService.UpdateSettings(uint(orgID), input); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при сохранении настроек организации")
+	}
+
+	return c.JSON(http.StatusOK, input)
+}
+
+func (h *OrganizationHandler) Promote(c echo.Context) error {
+	return h.changeRole(c, h.organizationService.Promote)
+}
+
+func (h *OrganizationHandler) Demote(c echo.Context) error {
+	return h.changeRole(c, h.organizationService.Demote)
+}
+
+func (h *OrganizationHandler) changeRole(c echo.Context, change func(actorID, orgID, targetID uint) (string, error)) error {
+	userID := c.Get("userID").(uint)
+
+	orgIDstr := c.Param("id")
+	orgID, err := strconv.ParseUint(orgIDstr, 10, 64)
+	if orgIDstr == "" || err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный запрос")
+	}
+
+	targetIDstr := c.Param("user_id")
+	targetID, err := strconv.ParseUint(targetIDstr, 10, 64)
+	if targetIDstr == "" || err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Некорректный ID пользователя")
+	}
+
+	role, err := change(userID, uint(orgID), uint(targetID))
+	if err != nil {
+		switch err.Error() {
+		case "access denied":
+			return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав для изменения роли этого участника")
+		case "user is not a member":
+			return echo.NewHTTPError(http.StatusNotFound, "Пользователь не состоит в организации")
+		case "role limit reached":
+			return echo.NewHTTPError(http.StatusConflict, "Роль участника уже минимальная")
+		case "organization not found":
+			return echo.NewHTTPError(http.StatusNotFound, "Организация не найдена")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при изменении роли участника")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"role": role})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"eventhub-backend/internal/domain"
 	"eventhub-backend/internal/service"
 	customJwt "eventhub-backend/pkg/jwt"
 	"net/http"
@@ -38,7 +39,7 @@ func (m *Middleware) AuthRequired(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-func (m *Middleware) IsOrganizationCreator(orgService *service.OrganizationService) echo.MiddlewareFunc {
+func (m *Middleware) RequireOrgPermission(orgService *service.OrganizationService, perm domain.Permission) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			userID := c.Get("userID").(uint)
@@ -49,12 +50,15 @@ func (m *Middleware) IsOrganizationCreator(orgService *service.OrganizationServi
 				return echo.NewHTTPError(http.StatusBadRequest, "Некорректный ID организации")
 			}
 
-			creatorID, err := orgService.GetCreator(uint(orgID))
+			allowed, err := orgService.HasPermission(uint(orgID), userID, perm)
 			if err != nil {
+				if err.Error() == "organization not found" {
+					return echo.NewHTTPError(http.StatusNotFound, "Организация не найдена")
+				}
 				return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка при проверке прав доступа")
 			}
 
-			if userID != creatorID {
+			if !allowed {
 				return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав для управления этой организацией")
 			}
 
@@ -0,0 +1,154 @@
+package policy
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+)
+
+// Actor описывает пользователя относительно конкретной организации и мероприятия
+type Actor struct {
+	UserID  uint
+	OrgRole string // пустая строка - пользователь не состоит в организации
+	Joined  bool
+	Host    bool // соорганизатор мероприятия
+}
+
+type Event struct {
+	CreatorID uint
+	IsPublic  bool
+	Status    string
+}
+
+type ForbiddenError struct {
+	Action string
+}
+
+// текст совпадает с прежней ошибкой сервисов, чтобы обработчики не ломались
+func (e *ForbiddenError) Error() string {
+	return "access denied"
+}
+
+func IsForbidden(err error) bool {
+	var forbidden *ForbiddenError
+	return errors.As(err, &forbidden)
+}
+
+func forbid(action string) error {
+	return &ForbiddenError{Action: action}
+}
+
+func (a Actor) isMember() bool {
+	return a.OrgRole != ""
+}
+
+// CanViewEvent - неопубликованное мероприятие видят только те, кто может его редактировать
+func CanViewEvent(actor Actor, event Event) error {
+	if domain.IsUnpublished(event.Status) {
+		if CanEditEvent(actor, event) != nil {
+			return forbid("view_event")
+		}
+		return nil
+	}
+
+	if event.IsPublic || actor.isMember() || actor.Joined || actor.Host || actor.UserID == event.CreatorID {
+		return nil
+	}
+
+	return forbid("view_event")
+}
+
+func CanJoinEvent(actor Actor, event Event) error {
+	if err := CanViewEvent(actor, event); err != nil {
+		return forbid("join_event")
+	}
+	if domain.IsUnpublished(event.Status) {
+		return forbid("join_event")
+	}
+
+	return nil
+}
+
+func CanListParticipants(actor Actor, event Event) error {
+	if err := CanViewEvent(actor, event); err != nil {
+		return forbid("list_participants")
+	}
+
+	return nil
+}
+
+func CanCommentEvent(actor Actor, event Event) error {
+	if err := CanViewEvent(actor, event); err != nil {
+		return forbid("comment_event")
+	}
+
+	return nil
+}
+
+// CanModerateComments - удалять чужие комментарии могут те, кто редактирует мероприятие
+func CanModerateComments(actor Actor, event Event) error {
+	if err := CanEditEvent(actor, event); err != nil {
+		return forbid("moderate_comments")
+	}
+
+	return nil
+}
+
+func CanEditEvent(actor Actor, event Event) error {
+	if actor.UserID == event.CreatorID || actor.Host || domain.HasPermission(actor.OrgRole, domain.PermEditAnyEvent) {
+		return nil
+	}
+
+	return forbid("edit_event")
+}
+
+// CanManageHosts - назначать соорганизаторов может создатель и администраторы организации
+func CanManageHosts(actor Actor, event Event) error {
+	if actor.UserID == event.CreatorID || domain.HasPermission(actor.OrgRole, domain.PermManageMembers) {
+		return nil
+	}
+
+	return forbid("manage_hosts")
+}
+
+// CanApproveEvent - одобрять и отклонять мероприятия могут роли с правом approve_events
+func CanApproveEvent(actor Actor, event Event) error {
+	if domain.HasPermission(actor.OrgRole, domain.PermApproveEvents) {
+		return nil
+	}
+
+	return forbid("approve_event")
+}
+
+func CanViewOrganization(actor Actor) error {
+	if actor.isMember() {
+		return nil
+	}
+
+	return forbid("view_organization")
+}
+
+func CanListMembers(actor Actor) error {
+	if actor.isMember() {
+		return nil
+	}
+
+	return forbid("list_members")
+}
+
+func CanInOrganization(actor Actor, perm domain.Permission) error {
+	if domain.HasPermission(actor.OrgRole, perm) {
+		return nil
+	}
+
+	return forbid(string(perm))
+}
+
+// CanDuplicateEvent - копия создается в той же организации, поэтому кроме прав
+// на мероприятие нужно право создавать мероприятия
+func CanDuplicateEvent(actor Actor, event Event) error {
+	if CanEditEvent(actor, event) != nil || !domain.HasPermission(actor.OrgRole, domain.PermCreateEvents) {
+		return forbid("duplicate_event")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"eventhub-backend/internal/domain"
+	"testing"
+)
+
+const creatorID = 1
+
+// участники проверки относительно организации мероприятия
+var actors = map[string]Actor{
+	"stranger":  {UserID: 10},
+	"member":    {UserID: 11, OrgRole: domain.RoleMember},
+	"joined":    {UserID: 12, Joined: true},
+	"creator":   {UserID: creatorID, OrgRole: domain.RoleMember},
+	"host":      {UserID: 13, OrgRole: domain.RoleMember, Host: true},
+	"moderator": {UserID: 14, OrgRole: domain.RoleModerator},
+	"admin":     {UserID: 15, OrgRole: domain.RoleAdmin},
+	"owner":     {UserID: 16, OrgRole: domain.RoleOwner},
+}
+
+func TestUnpublishedStatuses(t *testing.T) {
+	for _, status := range domain.UnpublishedStatuses {
+		event := Event{CreatorID: creatorID, IsPublic: true, Status: status}
+		if err := CanViewEvent(actors["member"], event); err == nil {
+			t.Errorf("участник видит мероприятие в статусе %s", status)
+		}
+		if err := CanJoinEvent(actors["creator"], event); err == nil {
+			t.Errorf("на мероприятие в статусе %s можно записаться", status)
+		}
+		if err := CanViewEvent(actors["host"], event); err != nil {
+			t.Errorf("соорганизатор не видит мероприятие в статусе %s", status)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import "time"
+
+// Виды файлов мероприятия
+const (
+	AttachmentCover = "cover"
+	AttachmentFile  = "file"
+)
+
+// EventAttachmentModel - файл мероприятия в BlobStore. Для картинок хранится
+// также уменьшенная копия.
+type EventAttachmentModel struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	EventID     uint      `gorm:"index" json:"event_id"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Key         string    `json:"-"`
+	URL         string    `json:"url"`
+	ThumbKey    string    `json:"-"`
+	ThumbURL    string    `json:"thumb_url,omitempty"`
+	UploadedBy  uint      `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (EventAttachmentModel) TableName() string {
+	return "event_attachments"
+}
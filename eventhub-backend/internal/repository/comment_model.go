@@ -0,0 +1,27 @@
+package repository
+
+import "time"
+
+// EventCommentModel - комментарий в обсуждении мероприятия. Ответ ссылается на
+// родителя через ParentID. Удаленный комментарий остается в базе, чтобы не рвать ветку.
+type EventCommentModel struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	EventID   uint       `gorm:"index:idx_comment_event_parent" json:"event_id"`
+	ParentID  *uint      `gorm:"index:idx_comment_event_parent" json:"parent_id"`
+	UserID    uint       `json:"user_id"`
+	Body      string     `gorm:"type:text" json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	DeletedBy *uint      `json:"deleted_by"`
+}
+
+func (EventCommentModel) TableName() string {
+	return "event_comments"
+}
+
+// CommentRow - комментарий вместе с числом прямых ответов
+type CommentRow struct {
+	EventCommentModel
+	ReplyCount int
+}
@@ -3,44 +3,176 @@ package repository
 import "time"
 
 type EventModel struct {
-	ID             uint      `gorm:"primaryKey"`
-	Title          string    `json:"title"`
-	Description    string    `json:"description"`
-	Category       string    `json:"category"`
-	IsPublic       bool      `json:"is_public"`
-	Status         string    `json:"status"`
-	Date           time.Time `gorm:"type:date" json:"date"`
-	StartTime      string    `gorm:"type:time" json:"start_time"`
-	EndTime        string    `gorm:"type:time" json:"end_time"`
-	Location       string    `json:"location"`
-	CreatorId      uint      `json:"creator_id"`
-	OrganizationId uint      `json:"organization_id"`
+	ID             uint       `gorm:"primaryKey"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Category       string     `json:"category"`
+	IsPublic       bool       `json:"is_public"`
+	Status         string     `json:"status"`
+	StartsAt       time.Time  `gorm:"type:timestamptz;index" json:"starts_at"`
+	EndsAt         time.Time  `gorm:"type:timestamptz" json:"ends_at"`
+	Timezone       string     `gorm:"default:UTC" json:"timezone"`
+	Location       string     `json:"location"`
+	CreatorId      uint       `json:"creator_id"`
+	OrganizationId uint       `json:"organization_id"`
+	Capacity       *int       `json:"capacity"`
+	SeriesId       *uint      `gorm:"index" json:"series_id"`
+	RecurrenceDate *time.Time `gorm:"type:date" json:"recurrence_date"`
+	UpdatedAt      time.Time  `gorm:"index" json:"updated_at"`
+	// средняя оценка из отзывов, пересчитывается при каждом новом отзыве
+	RatingAverage *float64 `json:"rating_average"`
+	RatingCount   int      `gorm:"default:0" json:"rating_count"`
+	// ссылки на обложку дублируются из event_attachments для списков и поиска
+	CoverURL      string `json:"cover_url"`
+	CoverThumbURL string `json:"cover_thumb_url"`
+	// время отложенной публикации для статуса scheduled
+	PublishAt *time.Time `gorm:"type:timestamptz;index" json:"publish_at"`
+	// причина последней смены статуса: отказа в одобрении, отмены или переноса
+	StatusReason    string     `json:"status_reason"`
+	StatusChangedAt *time.Time `gorm:"type:timestamptz" json:"status_changed_at"`
 }
 
+// EventResponse отдает время в часовом поясе мероприятия; date, end_date,
+// start_time и end_time - то же время в виде местных дат и часов
 type EventResponse struct {
-	ID             uint   `json:"id"`
-	Title          string `json:"title"`
-	Description    string `json:"description"`
-	Category       string `json:"category"`
-	IsPublic       bool   `json:"is_public"`
-	Status         string `json:"status"`
-	Date           string `json:"date"`
-	StartTime      string `json:"start_time"`
-	EndTime        string `json:"end_time"`
-	Location       string `json:"location"`
-	CreatorId      uint   `json:"creator_id"`
-	OrganizationId uint   `json:"organization_id"`
+	ID              uint       `json:"id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Category        string     `json:"category"`
+	IsPublic        bool       `json:"is_public"`
+	Status          string     `json:"status"`
+	StartsAt        time.Time  `json:"starts_at"`
+	EndsAt          time.Time  `json:"ends_at"`
+	Timezone        string     `json:"timezone"`
+	Date            string     `json:"date"`
+	EndDate         string     `json:"end_date"`
+	StartTime       string     `json:"start_time"`
+	EndTime         string     `json:"end_time"`
+	Location        string     `json:"location"`
+	CreatorId       uint       `json:"creator_id"`
+	OrganizationId  uint       `json:"organization_id"`
+	Capacity        *int       `json:"capacity"`
+	SeriesId        *uint      `json:"series_id"`
+	RRule           string     `json:"rrule,omitempty"`
+	RatingAverage   *float64   `json:"rating_average"`
+	RatingCount     int        `json:"rating_count"`
+	CoverURL        string     `json:"cover_url"`
+	CoverThumbURL   string     `json:"cover_thumb_url"`
+	PublishAt       *time.Time `json:"publish_at"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+	// вложения заполняются только в карточке мероприятия
+	Attachments []EventAttachmentModel `json:"attachments,omitempty"`
 }
 
 func (EventModel) TableName() string {
 	return "events"
 }
 
+// TimeLocation возвращает часовой пояс мероприятия; неизвестный пояс считается UTC
+func (e EventModel) TimeLocation() *time.Location {
+	return LoadLocation(e.Timezone)
+}
+
+func LoadLocation(name string) *time.Location {
+	if name == "" || name == "Local" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func newEventResponse(event EventModel) EventResponse {
+	loc := event.TimeLocation()
+	startsAt := event.StartsAt.In(loc)
+	endsAt := event.EndsAt.In(loc)
+
+	return EventResponse{
+		ID:              event.ID,
+		Title:           event.Title,
+		Description:     event.Description,
+		Category:        event.Category,
+		IsPublic:        event.IsPublic,
+		Status:          event.Status,
+		StartsAt:        startsAt,
+		EndsAt:          endsAt,
+		Timezone:        loc.String(),
+		Date:            startsAt.Format("2006-01-02"),
+		EndDate:         endsAt.Format("2006-01-02"),
+		StartTime:       startsAt.Format("15:04:05"),
+		EndTime:         endsAt.Format("15:04:05"),
+		Location:        event.Location,
+		CreatorId:       event.CreatorId,
+		OrganizationId:  event.OrganizationId,
+		Capacity:        event.Capacity,
+		SeriesId:        event.SeriesId,
+		RatingAverage:   event.RatingAverage,
+		RatingCount:     event.RatingCount,
+		CoverURL:        event.CoverURL,
+		CoverThumbURL:   event.CoverThumbURL,
+		PublishAt:       event.PublishAt,
+		StatusReason:    event.StatusReason,
+		StatusChangedAt: event.StatusChangedAt,
+	}
+}
+
+// EventParticipantModel - ответ пользователя на приглашение. Место занимают
+// только ответившие going; прежние записи без статуса считаются going.
 type EventParticipantModel struct {
-	UserID  uint `gorm:"primaryKey" json:"user_id"`
-	EventID uint `gorm:"primaryKey" json:"event_id"`
+	UserID      uint       `gorm:"primaryKey" json:"user_id"`
+	EventID     uint       `gorm:"primaryKey" json:"event_id"`
+	Status      string     `gorm:"default:going;index" json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+	Note        string     `json:"note"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	CheckedInBy *uint      `json:"checked_in_by"`
+	// ответы на анкету регистрации
+	Answers FormAnswers `gorm:"type:jsonb" json:"answers,omitempty"`
 }
 
 func (EventParticipantModel) TableName() string {
 	return "event_participants"
 }
+
+// AttendanceRow - сводка посещаемости одного мероприятия
+type AttendanceRow struct {
+	EventID   uint      `json:"event_id"`
+	Title     string    `json:"title"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Status    string    `json:"status"`
+	Going     int       `json:"going"`
+	CheckedIn int       `json:"checked_in"`
+}
+
+// EventHostModel - соорганизатор мероприятия; может редактировать его наравне с создателем
+type EventHostModel struct {
+	EventID   uint      `gorm:"primaryKey" json:"event_id"`
+	UserID    uint      `gorm:"primaryKey;index" json:"user_id"`
+	AddedBy   uint      `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EventHostModel) TableName() string {
+	return "event_hosts"
+}
+
+// EventWaitlistModel - очередь на мероприятие без свободных мест, порядок задает ID
+type EventWaitlistModel struct {
+	ID           uint  `gorm:"primaryKey" json:"id"`
+	EventID      uint  `gorm:"uniqueIndex:idx_waitlist_event_user" json:"event_id"`
+	UserID       uint  `gorm:"uniqueIndex:idx_waitlist_event_user" json:"user_id"`
+	TicketTypeID *uint `json:"ticket_type_id"` // билет выдается при переходе из очереди
+	// ответы на анкету переносятся в участника при переходе из очереди
+	Answers   FormAnswers `gorm:"type:jsonb" json:"answers,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (EventWaitlistModel) TableName() string {
+	return "event_waitlist"
+}
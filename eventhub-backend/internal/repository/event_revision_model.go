@@ -0,0 +1,82 @@
+package repository
+
+import "time"
+
+// EventRevisionModel - одна правка мероприятия: кто, когда и какие поля изменил
+type EventRevisionModel struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	EventID   uint          `gorm:"index" json:"event_id"`
+	ChangedBy *uint         `json:"changed_by"`
+	Changes   []FieldChange `gorm:"type:jsonb;serializer:json" json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func (EventRevisionModel) TableName() string {
+	return "event_revisions"
+}
+
+// FieldChange - прежнее и новое значение поля; время хранится в RFC 3339 (UTC)
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// о правке рассылается уведомление, только если изменилось время или место
+var noticeableFields = map[string]bool{"starts_at": true, "ends_at": true, "location": true}
+
+func (r EventRevisionModel) Noticeable() bool {
+	for _, change := range r.Changes {
+		if noticeableFields[change.Field] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diffEvent сравнивает поля, которые меняет организатор; имя поля совпадает с колонкой
+func diffEvent(before, after EventModel) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, old, new interface{}) {
+		changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+	}
+
+	if before.Title != after.Title {
+		add("title", before.Title, after.Title)
+	}
+	if before.Description != after.Description {
+		add("description", before.Description, after.Description)
+	}
+	if before.Category != after.Category {
+		add("category", before.Category, after.Category)
+	}
+	if before.IsPublic != after.IsPublic {
+		add("is_public", before.IsPublic, after.IsPublic)
+	}
+	if !before.StartsAt.Equal(after.StartsAt) {
+		add("starts_at", before.StartsAt.UTC(), after.StartsAt.UTC())
+	}
+	if !before.EndsAt.Equal(after.EndsAt) {
+		add("ends_at", before.EndsAt.UTC(), after.EndsAt.UTC())
+	}
+	if before.Timezone != after.Timezone {
+		add("timezone", before.Timezone, after.Timezone)
+	}
+	if before.Location != after.Location {
+		add("location", before.Location, after.Location)
+	}
+	if !equalCapacity(before.Capacity, after.Capacity) {
+		add("capacity", before.Capacity, after.Capacity)
+	}
+
+	return changes
+}
+
+func equalCapacity(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,11 @@
+package repository
+
+// EventSearchDocumentModel хранит полнотекстовый вектор мероприятия для поиска без Elasticsearch
+type EventSearchDocumentModel struct {
+	EventID  uint   `gorm:"primaryKey"`
+	Document string `gorm:"type:tsvector;index:idx_event_search_document,type:gin"`
+}
+
+func (EventSearchDocumentModel) TableName() string {
+	return "event_search_documents"
+}
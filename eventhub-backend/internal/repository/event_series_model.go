@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"eventhub-backend/internal/domain"
+	"time"
+)
+
+// EventSeriesModel - повторяющееся мероприятие. Вхождения хранятся обычными
+// строками events и создаются заранее до ExpandedUntil. Время начала задано
+// местным временем в Timezone, чтобы вхождения не сдвигались при переходе на летнее время.
+type EventSeriesModel struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	RRule          string    `gorm:"column:rrule" json:"rrule"`
+	StartDate      time.Time `gorm:"type:date" json:"start_date"`
+	ExpandedUntil  time.Time `gorm:"type:date" json:"expanded_until"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	IsPublic       bool      `json:"is_public"`
+	StartTime      string    `gorm:"type:time" json:"start_time"`
+	Duration       int64     `json:"duration"` // в секундах
+	Timezone       string    `gorm:"default:UTC" json:"timezone"`
+	Location       string    `json:"location"`
+	Capacity       *int      `json:"capacity"`
+	CreatorId      uint      `json:"creator_id"`
+	OrganizationId uint      `json:"organization_id"`
+}
+
+func (EventSeriesModel) TableName() string {
+	return "event_series"
+}
+
+// EventSeriesParticipantModel - подписка на все вхождения серии, включая будущие
+type EventSeriesParticipantModel struct {
+	UserID   uint `gorm:"primaryKey" json:"user_id"`
+	SeriesID uint `gorm:"primaryKey" json:"series_id"`
+}
+
+func (EventSeriesParticipantModel) TableName() string {
+	return "event_series_participants"
+}
+
+// occurrenceColumns - поля вхождения, которые задает серия
+var occurrenceColumns = []string{
+	"title", "description", "category", "is_public", "starts_at", "ends_at",
+	"timezone", "location", "capacity", "series_id", "recurrence_date",
+}
+
+// Occurrence создает строку мероприятия для одной даты серии
+func (s EventSeriesModel) Occurrence(date time.Time) EventModel {
+	seriesID := s.ID
+	recurrenceDate := date
+
+	clock, err := time.Parse("15:04:05", s.StartTime)
+	if err != nil {
+		clock = time.Time{}
+	}
+	startsAt := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, LoadLocation(s.Timezone))
+
+	return EventModel{
+		Title:          s.Title,
+		Description:    s.Description,
+		Category:       s.Category,
+		IsPublic:       s.IsPublic,
+		Status:         domain.EventStatusActive,
+		StartsAt:       startsAt.UTC(),
+		EndsAt:         startsAt.Add(time.Duration(s.Duration) * time.Second).UTC(),
+		Timezone:       s.Timezone,
+		Location:       s.Location,
+		CreatorId:      s.CreatorId,
+		OrganizationId: s.OrganizationId,
+		Capacity:       s.Capacity,
+		SeriesId:       &seriesID,
+		RecurrenceDate: &recurrenceDate,
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import "time"
+
+// EventStatusChangeModel - журнал переходов статуса мероприятия
+type EventStatusChangeModel struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EventID    uint      `gorm:"index" json:"event_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason,omitempty"`
+	ChangedBy  *uint     `json:"changed_by"` // nil - переход по расписанию
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (EventStatusChangeModel) TableName() string {
+	return "event_status_changes"
+}
+
+// StatusChange описывает переход статуса; Changes сохраняются вместе с ним
+type StatusChange struct {
+	To        string
+	Reason    string
+	ChangedBy *uint
+	Changes   map[string]interface{}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "time"
+
+// EventFeedbackModel - отзыв участника о прошедшем мероприятии, один на пользователя
+type EventFeedbackModel struct {
+	EventID   uint      `gorm:"primaryKey" json:"event_id"`
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `gorm:"type:text" json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EventFeedbackModel) TableName() string {
+	return "event_feedback"
+}
+
+// FeedbackSummary - сводка оценок мероприятия или организации
+type FeedbackSummary struct {
+	Average      *float64    `json:"average"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"` // оценка -> число отзывов
+}
+
+// EventFeedbackRow - сводка оценок одного мероприятия организации
+type EventFeedbackRow struct {
+	EventID  uint      `json:"event_id"`
+	Title    string    `json:"title"`
+	StartsAt time.Time `json:"starts_at"`
+	Average  *float64  `json:"average"`
+	Count    int       `json:"count"`
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// EventFormFieldModel - поле анкеты, которую заполняют при записи на мероприятие
+type EventFormFieldModel struct {
+	ID        uint     `gorm:"primaryKey" json:"id"`
+	EventID   uint     `gorm:"index" json:"event_id"`
+	Position  int      `json:"position"`
+	Label     string   `json:"label"`
+	Kind      string   `json:"kind"`
+	Required  bool     `json:"required"`
+	Options   []string `gorm:"type:jsonb;serializer:json" json:"options,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+}
+
+func (EventFormFieldModel) TableName() string {
+	return "event_form_fields"
+}
+
+// FormAnswers - ответы на анкету, ключ - ID поля. Значения уже проверены:
+// string для text и choice, bool для checkbox, float64 для number.
+type FormAnswers map[string]interface{}
+
+func (a FormAnswers) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(a)
+	return string(data), err
+}
+
+func (a *FormAnswers) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	}
+	return errors.New("unsupported answers value")
+}
+
+// FormAnswerRow - ответы одного записавшегося; Status - ответ на приглашение
+// или waitlisted для очереди
+type FormAnswerRow struct {
+	UserID  uint        `json:"user_id"`
+	Status  string      `json:"status"`
+	Answers FormAnswers `json:"answers"`
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (r *GormEventRepository) CreateAttachment(attachment *EventAttachmentModel) error {
+	return r.db.Create(attachment).Error
+}
+
+// GetAttachments возвращает файлы мероприятия без обложки
+func (r *GormEventRepository) GetAttachments(eventID uint) ([]EventAttachmentModel, error) {
+	attachments := []EventAttachmentModel{}
+	err := r.db.Where("event_id = ? AND kind = ?", eventID, AttachmentFile).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *GormEventRepository) GetAttachment(eventID, attachmentID uint) (EventAttachmentModel, error) {
+	var attachment EventAttachmentModel
+	if err := r.db.Where("id = ? AND event_id = ? AND kind = ?", attachmentID, eventID, AttachmentFile).First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return EventAttachmentModel{}, errors.New("attachment not found")
+		}
+		return EventAttachmentModel{}, err
+	}
+
+	return attachment, nil
+}
+
+func (r *GormEventRepository) DeleteAttachment(attachmentID uint) error {
+	return r.db.Delete(&EventAttachmentModel{}, attachmentID).Error
+}
+
+// SetCover заменяет обложку мероприятия (cover == nil - убирает ее) и возвращает
+// прежние записи, чтобы вызывающий удалил их файлы
+func (r *GormEventRepository) SetCover(eventID uint, cover *EventAttachmentModel) ([]EventAttachmentModel, error) {
+	var previous []EventAttachmentModel
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ? AND kind = ?", eventID, AttachmentCover).Find(&previous).Error; err != nil {
+			return err
+		}
+		if len(previous) > 0 {
+			if err := tx.Delete(&previous).Error; err != nil {
+				return err
+			}
+		}
+
+		urls := map[string]interface{}{"cover_url": "", "cover_thumb_url": ""}
+		if cover != nil {
+			if err := tx.Create(cover).Error; err != nil {
+				return err
+			}
+			urls = map[string]interface{}{"cover_url": cover.URL, "cover_thumb_url": cover.ThumbURL}
+		}
+
+		// updated_at меняется, чтобы обложка попала в поисковый индекс
+		return tx.Model(&EventModel{}).Where("id = ?", eventID).Updates(urls).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return previous, nil
+}
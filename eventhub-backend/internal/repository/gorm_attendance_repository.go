@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CheckIn отмечает приход участника. Повторная отметка ничего не меняет и
+// возвращает время первой с признаком true.
+func (r *GormEventRepository) CheckIn(userID, eventID, checkedInBy uint) (EventParticipantModel, bool, error) {
+	var participant EventParticipantModel
+	already := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND event_id = ? AND status = ?", userID, eventID, domain.RSVPGoing).
+			First(&participant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("user not joined")
+			}
+			return err
+		}
+
+		if participant.CheckedInAt != nil {
+			already = true
+			return nil
+		}
+
+		now := time.Now()
+		participant.CheckedInAt = &now
+		participant.CheckedInBy = &checkedInBy
+
+		return tx.Model(&EventParticipantModel{}).
+			Where("user_id = ? AND event_id = ?", userID, eventID).
+			Updates(map[string]interface{}{"checked_in_at": now, "checked_in_by": checkedInBy}).Error
+	})
+	if err != nil {
+		return EventParticipantModel{}, false, err
+	}
+
+	return participant, already, nil
+}
+
+// GetAttendance возвращает идущих на мероприятие вместе с отметками о приходе
+func (r *GormEventRepository) GetAttendance(eventID uint) ([]EventParticipantModel, error) {
+	var participants []EventParticipantModel
+	err := r.db.
+		Where("event_id = ? AND (status = ? OR checked_in_at IS NOT NULL)", eventID, domain.RSVPGoing).
+		Order("checked_in_at NULLS LAST, user_id").
+		Find(&participants).Error
+	return participants, err
+}
+
+// GetOrganizationAttendance сводит посещаемость по всем мероприятиям организации
+func (r *GormEventRepository) GetOrganizationAttendance(orgID uint) ([]AttendanceRow, error) {
+	var rows []AttendanceRow
+	err := r.db.Raw(`SELECT e.id AS event_id, e.title, e.starts_at, e.ends_at, e.status,
+		count(p.user_id) FILTER (WHERE p.status = @going) AS going,
+		count(p.checked_in_at) AS checked_in
+		FROM events e
+		LEFT JOIN event_participants p ON p.event_id = e.id
+		WHERE e.organization_id = @org_id AND e.status != 'deleted'
+		GROUP BY e.id
+		ORDER BY e.starts_at DESC`,
+		map[string]interface{}{"going": domain.RSVPGoing, "org_id": orgID}).
+		Scan(&rows).Error
+	return rows, err
+}
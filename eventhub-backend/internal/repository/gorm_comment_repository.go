@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetComments возвращает комментарии одного уровня ветки (parentID == nil - верхний)
+// по возрастанию ID после курсора. Удаленные комментарии без ответов не показываются.
+func (r *GormEventRepository) GetComments(eventID uint, parentID *uint, cursor uint, limit int) ([]CommentRow, error) {
+	query := r.db.Table("event_comments c").
+		Select("c.*, (SELECT count(*) FROM event_comments r WHERE r.parent_id = c.id) AS reply_count").
+		Where("c.event_id = ? AND c.id > ?", eventID, cursor).
+		Where("c.deleted_at IS NULL OR EXISTS (SELECT 1 FROM event_comments r WHERE r.parent_id = c.id)")
+
+	if parentID == nil {
+		query = query.Where("c.parent_id IS NULL")
+	} else {
+		query = query.Where("c.parent_id = ?", *parentID)
+	}
+
+	var rows []CommentRow
+	err := query.Order("c.id").Limit(limit).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormEventRepository) GetComment(eventID, commentID uint) (EventCommentModel, error) {
+	var comment EventCommentModel
+	if err := r.db.Where("id = ? AND event_id = ?", commentID, eventID).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return EventCommentModel{}, errors.New("comment not found")
+		}
+		return EventCommentModel{}, err
+	}
+
+	return comment, nil
+}
+
+// CreateComment сохраняет комментарий и уведомления упомянутым пользователям
+func (r *GormEventRepository) CreateComment(comment *EventCommentModel, mentionedIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return createMentions(tx, comment.EventID, mentionedIDs)
+	})
+}
+
+// UpdateComment меняет текст и уведомляет только новых упомянутых
+func (r *GormEventRepository) UpdateComment(comment *EventCommentModel, mentionedIDs []uint) error {
+	now := time.Now()
+	comment.EditedAt = &now
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&EventCommentModel{}).Where("id = ?", comment.ID).
+			Updates(map[string]interface{}{"body": comment.Body, "edited_at": now}).Error; err != nil {
+			return err
+		}
+		return createMentions(tx, comment.EventID, mentionedIDs)
+	})
+}
+
+func (r *GormEventRepository) DeleteComment(commentID, deletedBy uint) error {
+	return r.db.Model(&EventCommentModel{}).
+		Where("id = ? AND deleted_at IS NULL", commentID).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": deletedBy}).Error
+}
+
+// GetUserIDsByUsernames возвращает ID существующих пользователей с этими именами
+func (r *GormEventRepository) GetUserIDsByUsernames(usernames []string) ([]uint, error) {
+	userIDs := []uint{}
+	if len(usernames) == 0 {
+		return userIDs, nil
+	}
+
+	err := r.db.Model(&UserModel{}).Where("username IN ?", usernames).Pluck("id", &userIDs).Error
+	return userIDs, err
+}
+
+func createMentions(tx *gorm.DB, eventID uint, userIDs []uint) error {
+	for _, userID := range userIDs {
+		if err := tx.Create(&NotificationModel{UserID: userID, EventID: eventID, Type: "mention"}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
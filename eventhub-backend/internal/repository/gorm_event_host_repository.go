@@ -0,0 +1,43 @@
+package repository
+
+import "errors"
+
+func (r *GormEventRepository) IsUserHost(userID, eventID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&EventHostModel{}).Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetHosts возвращает соорганизаторов в порядке назначения
+func (r *GormEventRepository) GetHosts(eventID uint) ([]EventHostModel, error) {
+	var hosts []EventHostModel
+	err := r.db.Where("event_id = ?", eventID).Order("created_at").Find(&hosts).Error
+	return hosts, err
+}
+
+func (r *GormEventRepository) AddHost(eventID, userID, addedBy uint) error {
+	isHost, err := r.IsUserHost(userID, eventID)
+	if err != nil {
+		return err
+	}
+	if isHost {
+		return errors.New("user already host")
+	}
+
+	return r.db.Create(&EventHostModel{EventID: eventID, UserID: userID, AddedBy: addedBy}).Error
+}
+
+func (r *GormEventRepository) RemoveHost(eventID, userID uint) error {
+	result := r.db.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&EventHostModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not host")
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormEventRepository struct {
@@ -19,19 +20,7 @@ func NewGormEventRepository(db *gorm.DB) *GormEventRepository {
 func parseEventTime(events []EventModel) []EventResponse {
 	response := make([]EventResponse, 0, len(events))
 	for _, event := range events {
-		response = append(response, EventResponse{
-			ID:          event.ID,
-			Title:       event.Title,
-			Description: event.Description,
-			Category:    event.Category,
-			IsPublic:    event.IsPublic,
-			Status:      event.Status,
-			Date:        event.Date.Format("2006-01-02"),
-			StartTime:   event.StartTime,
-			EndTime:     event.EndTime,
-			Location:    event.Location,
-			CreatorId:   event.CreatorId,
-		})
+		response = append(response, newEventResponse(event))
 	}
 	return response
 }
@@ -46,22 +35,40 @@ func (r *GormEventRepository) GetAll() ([]EventModel, error) {
 	return events, nil
 }
 
+// GetChangedSince возвращает мероприятия, измененные после (updatedAt, lastID),
+// в порядке изменения. Свежие изменения младше before пропускаются, чтобы
+// не обогнать еще не закоммиченные транзакции.
+func (r *GormEventRepository) GetChangedSince(updatedAt time.Time, lastID uint, before time.Time, limit int) ([]EventModel, error) {
+	var events []EventModel
+
+	if err := r.db.
+		Where("(updated_at, id) > (?, ?)", updatedAt, lastID).
+		Where("updated_at < ?", before).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 func (r *GormEventRepository) GetAllUser(userID uint, userJoinedOrgs, userCreatorOrgs []uint) ([]EventResponse, []EventResponse, []EventResponse, error) {
 	var joinedEventIDs []uint
 	var joinedEvents, openEvents, availableClosedEvents []EventModel
 
-	// мероприятия, в которых участвует пользователь
-	if err := r.db.Table("event_participants").Where("user_id = ?", userID).Pluck("event_id", &joinedEventIDs).Error; err != nil {
+	// мероприятия, на которые пользователь идет; отказы и "может быть" в список не попадают
+	if err := r.db.Table("event_participants").Where("user_id = ? AND status = ?", userID, domain.RSVPGoing).Pluck("event_id", &joinedEventIDs).Error; err != nil {
 		return nil, nil, nil, err
 	}
 	if len(joinedEventIDs) > 0 {
-		if err := r.db.Where("id IN ?", joinedEventIDs).Find(&joinedEvents).Error; err != nil {
+		if err := r.db.Where("id IN ?", joinedEventIDs).Order("starts_at").Find(&joinedEvents).Error; err != nil {
 			return nil, nil, nil, err
 		}
 	}
 
-	// все открытые мероприятия
-	if err := r.db.Where("is_public = ?", true).Find(&openEvents).Error; err != nil {
+	// все открытые мероприятия; черновики и ожидающие публикации в списки не попадают
+	if err := r.db.Where("is_public = ?", true).Where("status NOT IN ?", domain.UnpublishedStatuses).Order("starts_at").Find(&openEvents).Error; err != nil {
 		return nil, nil, nil, err
 	}
 
@@ -74,12 +81,13 @@ func (r *GormEventRepository) GetAllUser(userID uint, userJoinedOrgs, userCreato
 			Model(&EventModel{}).
 			Where("organization_id IN ?", accessibleOrgIDs).
 			Where("is_public = ?", false).
+			Where("status NOT IN ?", domain.UnpublishedStatuses).
 			Pluck("id", &availableClosedEventIDs).Error; err != nil {
 			return nil, nil, nil, err
 		}
 
 		if len(availableClosedEventIDs) > 0 {
-			if err := r.db.Where("id IN ?", availableClosedEventIDs).Find(&availableClosedEvents).Error; err != nil {
+			if err := r.db.Where("id IN ?", availableClosedEventIDs).Order("starts_at").Find(&availableClosedEvents).Error; err != nil {
 				return nil, nil, nil, err
 			}
 		}
@@ -91,11 +99,11 @@ func (r *GormEventRepository) GetAllUser(userID uint, userJoinedOrgs, userCreato
 func (r *GormEventRepository) GetUpcoming() ([]EventModel, error) {
 	var events []EventModel
 
-	now := time.Now()
+	now := time.Now().UTC()
 	limit := now.Add(48 * time.Hour)
 
 	if err := r.db.
-		Where("(date + start_time) BETWEEN ? AND ?", now, limit).
+		Where("starts_at BETWEEN ? AND ?", now, limit).
 		Find(&events).Error; err != nil {
 		return nil, err
 	}
@@ -103,79 +111,356 @@ func (r *GormEventRepository) GetUpcoming() ([]EventModel, error) {
 	return events, nil
 }
 
-func (r *GormEventRepository) GetEventIDsToMarkCompleted() ([]EventModel, error) {
-	var events []EventModel
-	if err := r.db.Where("(date + start_time) <= ?", time.Now()).Where("status = ?", "active").Find(&events).Error; err != nil {
-		return nil, err
+// Join записывает пользователя на мероприятие, а если мест нет - в очередь.
+// Возвращает позицию в очереди; 0 означает, что пользователь стал участником.
+func (r *GormEventRepository) Join(userID, eventID uint, ticketTypeID *uint, answers FormAnswers) (int, error) {
+	position := 0
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		position, err = joinEvent(tx, userID, eventID, ticketTypeID, answers)
+		return err
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return events, nil
+	return position, nil
 }
 
-func (r *GormEventRepository) MarkEventsCompleted(events []EventModel) error {
-	var eventIDs []uint
-	for _, event := range events {
-		eventIDs = append(eventIDs, event.ID)
+// joinEvent занимает место и выдает билет выбранного типа (ticketTypeID == nil - без типа).
+// Место в очереди тоже расходует квоту типа, поэтому при переходе из очереди билет есть всегда.
+// Ответы на анкету сохраняются у участника или в очереди.
+func joinEvent(tx *gorm.DB, userID, eventID uint, ticketTypeID *uint, answers FormAnswers) (int, error) {
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		return 0, err
 	}
 
-	if err := r.db.Table("events").Where("id IN (?)", eventIDs).Update("status", "completed").Error; err != nil {
-		return err
+	if ticketTypeID != nil {
+		if err := reserveTicketType(tx, eventID, *ticketTypeID); err != nil {
+			return 0, err
+		}
 	}
 
-	return nil
+	freeSeats, err := countFreeSeats(tx, event)
+	if err != nil {
+		return 0, err
+	}
+
+	if freeSeats != 0 {
+		if err := saveResponse(tx, userID, eventID, domain.RSVPGoing, nil); err != nil {
+			return 0, err
+		}
+		if err := saveAnswers(tx, userID, eventID, answers); err != nil {
+			return 0, err
+		}
+		_, err := issueTicket(tx, userID, eventID, ticketTypeID)
+		return 0, err
+	}
+
+	return enqueue(tx, userID, eventID, ticketTypeID, answers)
+}
+
+func enqueue(tx *gorm.DB, userID, eventID uint, ticketTypeID *uint, answers FormAnswers) (int, error) {
+	entry := EventWaitlistModel{UserID: userID, EventID: eventID, TicketTypeID: ticketTypeID, Answers: answers}
+	if err := tx.Create(&entry).Error; err != nil {
+		return 0, err
+	}
+
+	return waitlistPosition(tx, entry)
 }
 
-func (r *GormEventRepository) Join(userID, eventID uint) error {
-	eventParticipant := EventParticipantModel{
-		UserID:  userID,
-		EventID: eventID,
+// saveResponse создает или обновляет ответ пользователя; note == nil оставляет прежнюю заметку
+func saveResponse(tx *gorm.DB, userID, eventID uint, status string, note *string) error {
+	now := time.Now()
+	response := EventParticipantModel{UserID: userID, EventID: eventID, Status: status, RespondedAt: &now}
+	columns := []string{"status", "responded_at"}
+	if note != nil {
+		response.Note = *note
+		columns = append(columns, "note")
 	}
 
-	if err := r.db.Create(&eventParticipant).Error; err != nil {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&response).Error
+}
+
+// SetRSVP сохраняет ответ на приглашение. Ответ going требует свободного
+// места: если его нет, прежний ответ остается, а пользователь встает в очередь.
+// Возвращает позицию в очереди или 0.
+func (r *GormEventRepository) SetRSVP(userID, eventID uint, status, note string) (int, error) {
+	position := 0
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+
+		var current EventParticipantModel
+		found := true
+		if err := tx.Where("user_id = ? AND event_id = ?", userID, eventID).First(&current).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			found = false
+		}
+
+		var entry EventWaitlistModel
+		waitlisted := true
+		if err := tx.Where("user_id = ? AND event_id = ?", userID, eventID).First(&entry).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			waitlisted = false
+		}
+
+		if status != domain.RSVPGoing {
+			if waitlisted {
+				if err := tx.Delete(&entry).Error; err != nil {
+					return err
+				}
+			}
+			if err := saveResponse(tx, userID, eventID, status, &note); err != nil {
+				return err
+			}
+			if err := revokeTickets(tx, userID, eventID); err != nil {
+				return err
+			}
+			if found && current.Status == domain.RSVPGoing {
+				return promoteWaitlisted(tx, event)
+			}
+			return nil
+		}
+
+		if found && current.Status == domain.RSVPGoing {
+			return saveResponse(tx, userID, eventID, status, &note)
+		}
+		if waitlisted {
+			position, err = waitlistPosition(tx, entry)
+			return err
+		}
+
+		freeSeats, err := countFreeSeats(tx, event)
+		if err != nil {
+			return err
+		}
+		if freeSeats != 0 {
+			return saveResponse(tx, userID, eventID, status, &note)
+		}
+
+		position, err = enqueue(tx, userID, eventID, nil, nil)
 		return err
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return position, nil
+}
+
+// GetRSVP возвращает ответ пользователя; false - пользователь не отвечал
+func (r *GormEventRepository) GetRSVP(userID, eventID uint) (EventParticipantModel, bool, error) {
+	var response EventParticipantModel
+	if err := r.db.Where("user_id = ? AND event_id = ?", userID, eventID).First(&response).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return EventParticipantModel{}, false, nil
+		}
+		return EventParticipantModel{}, false, err
+	}
+
+	return response, true, nil
+}
+
+func (r *GormEventRepository) GetResponses(eventID uint) ([]EventParticipantModel, error) {
+	var responses []EventParticipantModel
+	err := r.db.Where("event_id = ?", eventID).Order("responded_at").Find(&responses).Error
+	return responses, err
+}
+
+// GetWaitlistUserIDs возвращает очередь на мероприятие по порядку
+func (r *GormEventRepository) GetWaitlistUserIDs(eventID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&EventWaitlistModel{}).Where("event_id = ?", eventID).Order("id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
 }
 
+// Quit снимает пользователя с мероприятия или из очереди. Освободившееся место
+// в той же транзакции занимает первый из очереди.
 func (r *GormEventRepository) Quit(userID, eventID uint) error {
-	EventParticipant := EventParticipantModel{
-		UserID:  userID,
-		EventID: eventID,
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return quitEvent(tx, userID, eventID)
+	})
+}
+
+func quitEvent(tx *gorm.DB, userID, eventID uint) error {
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		return err
+	}
+
+	result := tx.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&EventParticipantModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		result = tx.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&EventWaitlistModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user not joined")
+		}
+		return nil
+	}
+
+	if err := revokeTickets(tx, userID, eventID); err != nil {
+		return err
+	}
+
+	return promoteWaitlisted(tx, event)
+}
+
+func (r *GormEventRepository) IsUserWaitlisted(userID, eventID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&EventWaitlistModel{}).Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetWaitlistPosition возвращает 0, если пользователя нет в очереди
+func (r *GormEventRepository) GetWaitlistPosition(userID, eventID uint) (int, error) {
+	var entry EventWaitlistModel
+	if err := r.db.Where("user_id = ? AND event_id = ?", userID, eventID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return waitlistPosition(r.db, entry)
+}
+
+// GetFreeSeats возвращает -1 для мероприятий без ограничения мест
+func (r *GormEventRepository) GetFreeSeats(eventID uint) (int, error) {
+	event, err := r.GetEventModelByID(eventID)
+	if err != nil {
+		return 0, err
+	}
+
+	return countFreeSeats(r.db, event)
+}
+
+func lockEvent(tx *gorm.DB, eventID uint) (EventModel, error) {
+	var event EventModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", eventID).First(&event).Error; err != nil {
+		return EventModel{}, err
+	}
+
+	return event, nil
+}
+
+func countFreeSeats(tx *gorm.DB, event EventModel) (int, error) {
+	if event.Capacity == nil {
+		return -1, nil
+	}
+
+	var participants int64
+	if err := tx.Model(&EventParticipantModel{}).Where("event_id = ? AND status = ?", event.ID, domain.RSVPGoing).Count(&participants).Error; err != nil {
+		return 0, err
+	}
+
+	return max(*event.Capacity-int(participants), 0), nil
+}
+
+func waitlistPosition(tx *gorm.DB, entry EventWaitlistModel) (int, error) {
+	var position int64
+	if err := tx.Model(&EventWaitlistModel{}).Where("event_id = ? AND id <= ?", entry.EventID, entry.ID).Count(&position).Error; err != nil {
+		return 0, err
+	}
+
+	return int(position), nil
+}
+
+// promoteWaitlisted переводит из очереди в участники столько пользователей,
+// сколько есть свободных мест, и создает им уведомления
+func promoteWaitlisted(tx *gorm.DB, event EventModel) error {
+	freeSeats, err := countFreeSeats(tx, event)
+	if err != nil {
+		return err
+	}
+	if freeSeats == 0 {
+		return nil
 	}
 
-	if err := r.db.Delete(&EventParticipant).Error; err != nil {
+	query := tx.Where("event_id = ?", event.ID).Order("id")
+	if freeSeats > 0 {
+		query = query.Limit(freeSeats)
+	}
+
+	var entries []EventWaitlistModel
+	if err := query.Find(&entries).Error; err != nil {
 		return err
 	}
 
+	for _, entry := range entries {
+		if err := tx.Delete(&entry).Error; err != nil {
+			return err
+		}
+		if err := saveResponse(tx, entry.UserID, event.ID, domain.RSVPGoing, nil); err != nil {
+			return err
+		}
+		if err := saveAnswers(tx, entry.UserID, event.ID, entry.Answers); err != nil {
+			return err
+		}
+		if _, err := issueTicket(tx, entry.UserID, event.ID, entry.TicketTypeID); err != nil {
+			return err
+		}
+		if err := tx.Create(&NotificationModel{UserID: entry.UserID, EventID: event.ID, Type: "waitlist_promoted"}).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (r *GormEventRepository) Create(input domain.CreateEventInput, creatorID, orgID uint) (*EventModel, error) {
-	event := EventModel{
+// Create сохраняет мероприятие в начальном статусе, который выбрал сервис
+func (r *GormEventRepository) Create(input domain.CreateEventInput, creatorID, orgID uint, status string) (*EventModel, error) {
+	event := newEventModel(input, creatorID, orgID, status)
+
+	if err := r.db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func newEventModel(input domain.CreateEventInput, creatorID, orgID uint, status string) EventModel {
+	return EventModel{
 		Title:          input.Title,
 		Description:    input.Description,
 		Category:       input.Category,
-		Status:         "active",
+		Status:         status,
+		PublishAt:      input.PublishAt,
 		Location:       input.Location,
-		IsPublic:       input.IsPublic,
+		IsPublic:       input.Public(),
 		CreatorId:      creatorID,
-		Date:           input.Date,
-		StartTime:      input.StartTime,
-		EndTime:        input.EndTime,
+		StartsAt:       input.StartsAt.UTC(),
+		EndsAt:         input.EndsAt.UTC(),
+		Timezone:       input.Timezone,
 		OrganizationId: orgID,
+		Capacity:       input.Capacity,
 	}
-
-	if err := r.db.Create(&event).Error; err != nil {
-		return nil, err
-	}
-
-	return &event, nil
 }
 
 func (r *GormEventRepository) IsEventExist(eventID uint) (bool, error) {
 	var event EventModel
-	if err := r.db.Where("id = ? AND status != ?", eventID, "deleted").First(&event).Error; err != nil {
+	if err := r.db.Where("id = ? AND status != ?", eventID, domain.EventStatusDeleted).First(&event).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, nil
 		}
@@ -184,9 +469,10 @@ func (r *GormEventRepository) IsEventExist(eventID uint) (bool, error) {
 	return true, nil
 }
 
+// IsUserJoined проверяет, что пользователь идет на мероприятие (ответ going)
 func (r *GormEventRepository) IsUserJoined(userID, eventID uint) (bool, error) {
 	var eventParticipant EventParticipantModel
-	if err := r.db.Where("user_id = ? AND event_id = ?", userID, eventID).First(&eventParticipant).Error; err != nil {
+	if err := r.db.Where("user_id = ? AND event_id = ? AND status = ?", userID, eventID, domain.RSVPGoing).First(&eventParticipant).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, nil
 		}
@@ -196,26 +482,45 @@ func (r *GormEventRepository) IsUserJoined(userID, eventID uint) (bool, error) {
 	return true, nil
 }
 
-func (r *GormEventRepository) GetByID(eventID, userID uint) (EventResponse, bool, error) {
+// GetByID возвращает мероприятие и роль пользователя в нем: creator, host или пустую строку
+func (r *GormEventRepository) GetByID(eventID, userID uint) (EventResponse, string, error) {
 	var event EventModel
 	if err := r.db.Where("id = ?", eventID).First(&event).Error; err != nil {
-		return EventResponse{}, false, err
+		return EventResponse{}, "", err
+	}
+
+	var rrule string
+	if event.SeriesId != nil {
+		var series EventSeriesModel
+		if err := r.db.Where("id = ?", *event.SeriesId).First(&series).Error; err != nil {
+			return EventResponse{}, "", err
+		}
+		rrule = series.RRule
+	}
+
+	response := newEventResponse(event)
+	response.RRule = rrule
+
+	attachments, err := r.GetAttachments(eventID)
+	if err != nil {
+		return EventResponse{}, "", err
+	}
+	response.Attachments = attachments
+
+	role := ""
+	if userID == event.CreatorId {
+		role = domain.EventRoleCreator
+	} else {
+		isHost, err := r.IsUserHost(userID, eventID)
+		if err != nil {
+			return EventResponse{}, "", err
+		}
+		if isHost {
+			role = domain.EventRoleHost
+		}
 	}
 
-	return EventResponse{
-		ID:             event.ID,
-		Title:          event.Title,
-		Description:    event.Description,
-		Category:       event.Category,
-		IsPublic:       event.IsPublic,
-		Status:         event.Status,
-		Date:           event.Date.Format("2006-01-02"),
-		StartTime:      event.StartTime,
-		EndTime:        event.EndTime,
-		Location:       event.Location,
-		CreatorId:      event.CreatorId,
-		OrganizationId: event.OrganizationId,
-	}, userID == event.CreatorId, nil
+	return response, role, nil
 }
 
 func (r *GormEventRepository) GetEventModelByID(eventID uint) (EventModel, error) {
@@ -227,31 +532,44 @@ func (r *GormEventRepository) GetEventModelByID(eventID uint) (EventModel, error
 	return event, nil
 }
 
+// GetParticipantIDs возвращает всех, кто не отказался от мероприятия
 func (r *GormEventRepository) GetParticipantIDs(eventID uint) ([]uint, error) {
+	return r.GetParticipantIDsByStatus(eventID, []string{domain.RSVPGoing, domain.RSVPMaybe})
+}
+
+func (r *GormEventRepository) GetParticipantIDsByStatus(eventID uint, statuses []string) ([]uint, error) {
 	var participantIDs []uint
-	err := r.db.Table("event_participants").Where("event_id = ?", eventID).Pluck("user_id", &participantIDs).Error
+	err := r.db.Table("event_participants").Where("event_id = ? AND status IN ?", eventID, statuses).Pluck("user_id", &participantIDs).Error
 	return participantIDs, err
 }
 
-func (r *GormEventRepository) Delete(eventID uint) error {
-	return r.db.Model(&EventModel{}).
-		Where("id = ?", eventID).
-		Update("status", "deleted").Error
+func (r *GormEventRepository) Delete(eventID, userID uint) error {
+	_, err := r.TransitionStatus(eventID, StatusChange{To: domain.EventStatusDeleted, ChangedBy: &userID})
+	return err
 }
 
-func (r *GormEventRepository) IsUserCreator(userID, eventID uint) (bool, error) {
-	var event EventModel
+// Update сохраняет только изменившиеся поля мероприятия и записывает правку в
+// event_revisions; если мест стало больше, очередь продвигается в той же транзакции
+func (r *GormEventRepository) Update(event *EventModel, changedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockEvent(tx, event.ID)
+		if err != nil {
+			return err
+		}
 
-	if err := r.db.Where("id = ?", eventID).First(&event).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil
+		changes := diffEvent(before, *event)
+		if len(changes) == 0 {
+			return nil
 		}
-		return false, err
-	}
 
-	return event.CreatorId == userID, nil
-}
+		if err := tx.Model(&before).Select(changedColumns(changes)).Updates(event).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, event.ID, &changedBy, changes); err != nil {
+			return err
+		}
 
-func (r *GormEventRepository) Update(event *EventModel) error {
-	return r.db.Save(event).Error
+		return promoteWaitlisted(tx, *event)
+	})
 }
@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"eventhub-backend/internal/database"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// В "мои мероприятия" попадают только те, на которые пользователь идет
+func TestGetAllUserJoinedOnlyGoing(t *testing.T) {
+	db := database.OpenTest(t)
+	repo := repository.NewGormEventRepository(db)
+
+	user := repository.UserModel{FirstName: "user", Username: "user-joined-test"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	org := repository.OrganizationModel{Name: "org-joined-test", FounderID: user.ID, InviteCode: "code-joined-test"}
+	if err := db.Create(&org).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	startsAt := time.Now().Add(72 * time.Hour).Truncate(time.Second).UTC()
+	statuses := []string{domain.RSVPGoing, domain.RSVPMaybe, domain.RSVPDeclined}
+	eventIDs := make(map[string]uint, len(statuses))
+	for _, status := range statuses {
+		event := repository.EventModel{
+			Title:          status,
+			Status:         domain.EventStatusActive,
+			IsPublic:       true,
+			StartsAt:       startsAt,
+			EndsAt:         startsAt.Add(2 * time.Hour),
+			Timezone:       "UTC",
+			CreatorId:      user.ID,
+			OrganizationId: org.ID,
+		}
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now()
+		if err := db.Create(&repository.EventParticipantModel{EventID: event.ID, UserID: user.ID, Status: status, RespondedAt: &now}).Error; err != nil {
+			t.Fatal(err)
+		}
+		eventIDs[status] = event.ID
+	}
+
+	joined, _, _, err := repo.GetAllUser(user.ID, nil, []uint{org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joined) != 1 || joined[0].ID != eventIDs[domain.RSVPGoing] {
+		t.Fatalf("в списке участия %+v, ожидалось только мероприятие %d", joined, eventIDs[domain.RSVPGoing])
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"eventhub-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+func (r *GormEventRepository) GetRevisions(eventID uint) ([]EventRevisionModel, error) {
+	revisions := []EventRevisionModel{}
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *GormEventRepository) GetRevision(revisionID uint) (EventRevisionModel, error) {
+	var revision EventRevisionModel
+	err := r.db.Where("id = ?", revisionID).First(&revision).Error
+	return revision, err
+}
+
+// saveRevision записывает правку и, если изменились время или место,
+// уведомляет участников со ссылкой на нее. Пустая правка не записывается.
+func saveRevision(tx *gorm.DB, eventID uint, changedBy *uint, changes []FieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	revision := EventRevisionModel{EventID: eventID, ChangedBy: changedBy, Changes: changes}
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+	if !revision.Noticeable() {
+		return nil
+	}
+
+	return notifyParticipants(tx, eventID, "reschedule", &revision.ID)
+}
+
+// notifyParticipants уведомляет всех, кто не отказался от мероприятия
+func notifyParticipants(tx *gorm.DB, eventID uint, notificationType string, revisionID *uint) error {
+	var userIDs []uint
+	if err := tx.Model(&EventParticipantModel{}).
+		Where("event_id = ? AND status IN ?", eventID, []string{domain.RSVPGoing, domain.RSVPMaybe}).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		notification := NotificationModel{UserID: userID, EventID: eventID, Type: notificationType, RevisionID: revisionID}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func changedColumns(changes []FieldChange) []string {
+	columns := make([]string, 0, len(changes))
+	for _, change := range changes {
+		columns = append(columns, change.Field)
+	}
+
+	return columns
+}
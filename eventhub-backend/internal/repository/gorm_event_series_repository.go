@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSeries сохраняет серию и ее первые вхождения
+func (r *GormEventRepository) CreateSeries(series *EventSeriesModel, dates []time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+
+		for _, date := range dates {
+			occurrence := series.Occurrence(date)
+			if err := tx.Create(&occurrence).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *GormEventRepository) GetSeries(seriesID uint) (EventSeriesModel, error) {
+	var series EventSeriesModel
+	if err := r.db.Where("id = ?", seriesID).First(&series).Error; err != nil {
+		return EventSeriesModel{}, err
+	}
+
+	return series, nil
+}
+
+// GetSeriesToExpand возвращает серии, вхождения которых созданы не до horizon
+func (r *GormEventRepository) GetSeriesToExpand(horizon time.Time) ([]EventSeriesModel, error) {
+	var series []EventSeriesModel
+	if err := r.db.Where("expanded_until < ?", horizon).Find(&series).Error; err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// AddOccurrences создает новые вхождения серии и записывает на них подписчиков серии
+func (r *GormEventRepository) AddOccurrences(series EventSeriesModel, dates []time.Time, expandedUntil time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createOccurrences(tx, series, dates); err != nil {
+			return err
+		}
+
+		return tx.Model(&EventSeriesModel{}).
+			Where("id = ?", series.ID).
+			Update("expanded_until", expandedUntil).Error
+	})
+}
+
+func createOccurrences(tx *gorm.DB, series EventSeriesModel, dates []time.Time) error {
+	var subscriberIDs []uint
+	if err := tx.Model(&EventSeriesParticipantModel{}).Where("series_id = ?", series.ID).Pluck("user_id", &subscriberIDs).Error; err != nil {
+		return err
+	}
+
+	for _, date := range dates {
+		occurrence := series.Occurrence(date)
+		if err := tx.Create(&occurrence).Error; err != nil {
+			return err
+		}
+
+		// у только что созданного вхождения еще нет ни типов билетов, ни
+		// анкеты, поэтому подписчики записываются без типа и ответов
+		for _, userID := range subscriberIDs {
+			if _, err := joinEvent(tx, userID, occurrence.ID, nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetFollowingIDs возвращает неудаленные вхождения серии начиная с даты from
+func (r *GormEventRepository) GetFollowingIDs(seriesID uint, from time.Time) ([]uint, error) {
+	var eventIDs []uint
+	err := r.db.Model(&EventModel{}).
+		Where("series_id = ? AND recurrence_date >= ? AND status != ?", seriesID, from, domain.EventStatusDeleted).
+		Order("recurrence_date").
+		Pluck("id", &eventIDs).Error
+	return eventIDs, err
+}
+
+// CancelFollowing удаляет вхождения начиная с from и обрезает правило серии
+func (r *GormEventRepository) CancelFollowing(seriesID uint, from time.Time, truncatedRRule string, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		_, err := truncateSeries(tx, seriesID, from, truncatedRRule, &userID, true)
+		return err
+	})
+}
+
+// truncateSeries обрезает правило серии; с remove вхождения начиная с from удаляются
+// и возвращаются (changedBy == nil - по изменению правила повторения)
+func truncateSeries(tx *gorm.DB, seriesID uint, from time.Time, truncatedRRule string, changedBy *uint, remove bool) ([]uint, error) {
+	if err := tx.Model(&EventSeriesModel{}).Where("id = ?", seriesID).Update("rrule", truncatedRRule).Error; err != nil {
+		return nil, err
+	}
+	if !remove {
+		return nil, nil
+	}
+
+	var eventIDs []uint
+	if err := tx.Model(&EventModel{}).
+		Where("series_id = ? AND recurrence_date >= ? AND status != ?", seriesID, from, domain.EventStatusDeleted).
+		Pluck("id", &eventIDs).Error; err != nil {
+		return nil, err
+	}
+
+	for _, eventID := range eventIDs {
+		if _, err := transitionStatus(tx, eventID, StatusChange{To: domain.EventStatusDeleted, ChangedBy: changedBy}); err != nil {
+			return nil, err
+		}
+	}
+
+	return eventIDs, nil
+}
+
+func copySubscriptions(tx *gorm.DB, fromSeriesID, toSeriesID uint) error {
+	var subscriberIDs []uint
+	if err := tx.Model(&EventSeriesParticipantModel{}).Where("series_id = ?", fromSeriesID).Pluck("user_id", &subscriberIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range subscriberIDs {
+		if err := tx.Create(&EventSeriesParticipantModel{UserID: userID, SeriesID: toSeriesID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SplitSeries переносит вхождения начиная с from в новую серию next с тем же
+// шагом повторения. Вхождения получают поля новой серии и сдвигаются на
+// shiftDays дней, участники и очередь сохраняются. Правка каждого вхождения
+// записывается в его историю.
+func (r *GormEventRepository) SplitSeries(seriesID uint, from time.Time, truncatedRRule string, next *EventSeriesModel, shiftDays int, changedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := truncateSeries(tx, seriesID, from, truncatedRRule, nil, false); err != nil {
+			return err
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		if err := copySubscriptions(tx, seriesID, next.ID); err != nil {
+			return err
+		}
+
+		var events []EventModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("series_id = ? AND recurrence_date >= ? AND status != ?", seriesID, from, domain.EventStatusDeleted).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			// статус, оценки и обложка вхождения не зависят от серии и сохраняются
+			occurrence := next.Occurrence(event.RecurrenceDate.AddDate(0, 0, shiftDays))
+			occurrence.ID = event.ID
+			changes := diffEvent(event, occurrence)
+			if err := tx.Model(&event).Select(occurrenceColumns).Updates(&occurrence).Error; err != nil {
+				return err
+			}
+			if err := saveRevision(tx, event.ID, &changedBy, changes); err != nil {
+				return err
+			}
+
+			if err := promoteWaitlisted(tx, occurrence); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReplaceFollowing используется при смене правила повторения: вхождения начиная
+// с from отменяются, а новая серия next создает свои на даты dates. Участники
+// отмененных вхождений получают уведомление о переносе без правки: прежних
+// дат у новых вхождений нет.
+func (r *GormEventRepository) ReplaceFollowing(seriesID uint, from time.Time, truncatedRRule string, next *EventSeriesModel, dates []time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		removedIDs, err := truncateSeries(tx, seriesID, from, truncatedRRule, nil, true)
+		if err != nil {
+			return err
+		}
+		for _, eventID := range removedIDs {
+			if err := notifyParticipants(tx, eventID, "reschedule", nil); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		if err := copySubscriptions(tx, seriesID, next.ID); err != nil {
+			return err
+		}
+
+		return createOccurrences(tx, *next, dates)
+	})
+}
+
+func (r *GormEventRepository) IsUserSubscribed(userID, seriesID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&EventSeriesParticipantModel{}).Where("user_id = ? AND series_id = ?", userID, seriesID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// JoinSeries подписывает пользователя на серию и записывает на все вхождения, начинающиеся после from
+func (r *GormEventRepository) JoinSeries(userID, seriesID uint, from time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&EventSeriesParticipantModel{UserID: userID, SeriesID: seriesID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user already joined")
+		}
+
+		var eventIDs []uint
+		if err := tx.Model(&EventModel{}).
+			Where("series_id = ? AND starts_at >= ? AND status = ?", seriesID, from, domain.EventStatusActive).
+			Where("id NOT IN (?)", tx.Model(&EventParticipantModel{}).Select("event_id").Where("user_id = ?", userID)).
+			Where("id NOT IN (?)", tx.Model(&EventWaitlistModel{}).Select("event_id").Where("user_id = ?", userID)).
+			Pluck("id", &eventIDs).Error; err != nil {
+			return err
+		}
+
+		required, err := requiresRegistration(tx, eventIDs)
+		if err != nil {
+			return err
+		}
+		if required {
+			return errors.New("series registration required")
+		}
+
+		for _, eventID := range eventIDs {
+			if _, err := joinEvent(tx, userID, eventID, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// requiresRegistration - подписка на серию выдает билеты без типа и не
+// заполняет анкету, поэтому на вхождения с типами билетов или обязательными
+// полями анкеты записываются по одному
+func requiresRegistration(tx *gorm.DB, eventIDs []uint) (bool, error) {
+	if len(eventIDs) == 0 {
+		return false, nil
+	}
+
+	var typed int64
+	if err := tx.Model(&EventTicketTypeModel{}).Where("event_id IN ?", eventIDs).Count(&typed).Error; err != nil {
+		return false, err
+	}
+	if typed > 0 {
+		return true, nil
+	}
+
+	var required int64
+	if err := tx.Model(&EventFormFieldModel{}).Where("event_id IN ? AND required", eventIDs).Count(&required).Error; err != nil {
+		return false, err
+	}
+
+	return required > 0, nil
+}
+
+// QuitSeries отменяет подписку на серию и запись на вхождения, начинающиеся после from
+func (r *GormEventRepository) QuitSeries(userID, seriesID uint, from time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND series_id = ?", userID, seriesID).Delete(&EventSeriesParticipantModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user not joined")
+		}
+
+		var eventIDs []uint
+		if err := tx.Model(&EventModel{}).
+			Where("series_id = ? AND starts_at >= ? AND status = ?", seriesID, from, domain.EventStatusActive).
+			Pluck("id", &eventIDs).Error; err != nil {
+			return err
+		}
+
+		for _, eventID := range eventIDs {
+			if err := quitEvent(tx, userID, eventID); err != nil && err.Error() != "user not joined" {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
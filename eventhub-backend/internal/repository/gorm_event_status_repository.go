@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransitionStatus переводит мероприятие в новый статус, если переход разрешен
+func (r *GormEventRepository) TransitionStatus(eventID uint, change StatusChange) (EventModel, error) {
+	var event EventModel
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		event, err = transitionStatus(tx, eventID, change)
+		return err
+	})
+	if err != nil {
+		return EventModel{}, err
+	}
+
+	return event, nil
+}
+
+// ReviewEvent меняет статус по решению администратора и уведомляет создателя
+func (r *GormEventRepository) ReviewEvent(eventID uint, change StatusChange, notificationType string) (EventModel, error) {
+	var event EventModel
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		event, err = transitionStatus(tx, eventID, change)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&NotificationModel{UserID: event.CreatorId, EventID: eventID, Type: notificationType}).Error
+	})
+	if err != nil {
+		return EventModel{}, err
+	}
+
+	return event, nil
+}
+
+// GetEventsToPublish возвращает запланированные мероприятия, время публикации которых наступило
+func (r *GormEventRepository) GetEventsToPublish() ([]EventModel, error) {
+	var events []EventModel
+	err := r.db.Where("status = ? AND publish_at <= ?", domain.EventStatusScheduled, time.Now().UTC()).Find(&events).Error
+	return events, err
+}
+
+// GetEventsToStart возвращает опубликованные мероприятия, которые уже начались
+func (r *GormEventRepository) GetEventsToStart() ([]EventModel, error) {
+	var events []EventModel
+	now := time.Now().UTC()
+	err := r.db.Where("status = ? AND starts_at <= ? AND ends_at > ?", domain.EventStatusActive, now, now).Find(&events).Error
+	return events, err
+}
+
+// GetEventsToComplete возвращает мероприятия, время окончания которых прошло;
+// многодневные остаются идущими до своего окончания
+func (r *GormEventRepository) GetEventsToComplete() ([]EventModel, error) {
+	var events []EventModel
+	err := r.db.
+		Where("status IN ? AND ends_at <= ?", []string{domain.EventStatusActive, domain.EventStatusOngoing}, time.Now().UTC()).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *GormEventRepository) GetStatusChanges(eventID uint) ([]EventStatusChangeModel, error) {
+	changes := []EventStatusChangeModel{}
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&changes).Error
+	return changes, err
+}
+
+// transitionStatus - единственное место, где меняется статус мероприятия:
+// проверяет переход по domain.CanTransition и записывает его в журнал
+func transitionStatus(tx *gorm.DB, eventID uint, change StatusChange) (EventModel, error) {
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		return EventModel{}, err
+	}
+	if !domain.CanTransition(event.Status, change.To) {
+		return EventModel{}, errors.New("invalid status transition")
+	}
+
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"status":            change.To,
+		"status_reason":     change.Reason,
+		"status_changed_at": now,
+	}
+	for column, value := range change.Changes {
+		updates[column] = value
+	}
+
+	from := event.Status
+	if err := tx.Model(&event).Updates(updates).Error; err != nil {
+		return EventModel{}, err
+	}
+
+	if err := tx.Create(&EventStatusChangeModel{
+		EventID:    eventID,
+		FromStatus: from,
+		ToStatus:   change.To,
+		Reason:     change.Reason,
+		ChangedBy:  change.ChangedBy,
+	}).Error; err != nil {
+		return EventModel{}, err
+	}
+
+	event.Status = change.To
+	event.StatusReason = change.Reason
+	event.StatusChangedAt = &now
+	return event, nil
+}
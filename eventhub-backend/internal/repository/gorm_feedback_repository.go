@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateFeedback сохраняет отзыв и пересчитывает среднюю оценку мероприятия.
+// updated_at меняется, чтобы оценка попала в поисковый индекс.
+func (r *GormEventRepository) CreateFeedback(feedback *EventFeedbackModel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(feedback)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("feedback already submitted")
+		}
+
+		return tx.Exec(`UPDATE events SET
+			rating_average = (SELECT avg(rating) FROM event_feedback WHERE event_id = @id),
+			rating_count = (SELECT count(*) FROM event_feedback WHERE event_id = @id),
+			updated_at = now()
+			WHERE id = @id`, map[string]interface{}{"id": feedback.EventID}).Error
+	})
+}
+
+func (r *GormEventRepository) HasFeedback(userID, eventID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&EventFeedbackModel{}).Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *GormEventRepository) GetFeedback(eventID uint) ([]EventFeedbackModel, error) {
+	var feedback []EventFeedbackModel
+	err := r.db.Where("event_id = ?", eventID).Order("created_at DESC").Find(&feedback).Error
+	return feedback, err
+}
+
+// GetOrganizationFeedback возвращает сводку по организации и по каждому ее мероприятию с отзывами
+func (r *GormEventRepository) GetOrganizationFeedback(orgID uint) (FeedbackSummary, []EventFeedbackRow, error) {
+	var rows []EventFeedbackRow
+	if err := r.db.Raw(`SELECT e.id AS event_id, e.title, e.starts_at,
+		avg(f.rating) AS average, count(*) AS count
+		FROM event_feedback f
+		JOIN events e ON e.id = f.event_id
+		WHERE e.organization_id = ?
+		GROUP BY e.id
+		ORDER BY e.starts_at DESC`, orgID).Scan(&rows).Error; err != nil {
+		return FeedbackSummary{}, nil, err
+	}
+
+	var ratings []int
+	if err := r.db.Model(&EventFeedbackModel{}).
+		Joins("JOIN events e ON e.id = event_feedback.event_id").
+		Where("e.organization_id = ?", orgID).
+		Pluck("rating", &ratings).Error; err != nil {
+		return FeedbackSummary{}, nil, err
+	}
+
+	return summarize(ratings), rows, nil
+}
+
+func summarize(ratings []int) FeedbackSummary {
+	summary := FeedbackSummary{Count: len(ratings), Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
+	if len(ratings) == 0 {
+		return summary
+	}
+
+	total := 0
+	for _, rating := range ratings {
+		total += rating
+		summary.Distribution[rating]++
+	}
+	average := float64(total) / float64(len(ratings))
+	summary.Average = &average
+
+	return summary
+}
+
+// SummarizeFeedback считает сводку по уже загруженным отзывам
+func SummarizeFeedback(feedback []EventFeedbackModel) FeedbackSummary {
+	ratings := make([]int, 0, len(feedback))
+	for _, f := range feedback {
+		ratings = append(ratings, f.Rating)
+	}
+
+	return summarize(ratings)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+func (r *GormEventRepository) GetFormFields(eventID uint) ([]EventFormFieldModel, error) {
+	fields := []EventFormFieldModel{}
+	err := r.db.Where("event_id = ?", eventID).Order("position, id").Find(&fields).Error
+	return fields, err
+}
+
+// SaveFormFields заменяет анкету: поля с ID обновляются, без ID - создаются,
+// отсутствующие в списке удаляются. Ответы на удаленные поля остаются в
+// записях участников, но больше не показываются.
+func (r *GormEventRepository) SaveFormFields(eventID uint, fields []EventFormFieldModel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		keep := []uint{}
+		for _, field := range fields {
+			if field.ID != 0 {
+				keep = append(keep, field.ID)
+			}
+		}
+
+		query := tx.Where("event_id = ?", eventID)
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		if err := query.Delete(&EventFormFieldModel{}).Error; err != nil {
+			return err
+		}
+
+		for i := range fields {
+			fields[i].EventID = eventID
+			fields[i].Position = i
+			if err := tx.Save(&fields[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetFormAnswers возвращает ответы записавшихся и стоящих в очереди
+func (r *GormEventRepository) GetFormAnswers(eventID uint) ([]FormAnswerRow, error) {
+	var participants []EventParticipantModel
+	if err := r.db.Where("event_id = ?", eventID).Order("responded_at").Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	var waitlist []EventWaitlistModel
+	if err := r.db.Where("event_id = ?", eventID).Order("id").Find(&waitlist).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]FormAnswerRow, 0, len(participants)+len(waitlist))
+	for _, participant := range participants {
+		rows = append(rows, FormAnswerRow{UserID: participant.UserID, Status: participant.Status, Answers: participant.Answers})
+	}
+	for _, entry := range waitlist {
+		rows = append(rows, FormAnswerRow{UserID: entry.UserID, Status: "waitlisted", Answers: entry.Answers})
+	}
+
+	return rows, nil
+}
+
+// saveAnswers записывает ответы участнику; nil оставляет прежние
+func saveAnswers(tx *gorm.DB, userID, eventID uint, answers FormAnswers) error {
+	if answers == nil {
+		return nil
+	}
+
+	return tx.Model(&EventParticipantModel{}).
+		Where("user_id = ? AND event_id = ?", userID, eventID).
+		Update("answers", answers).Error
+}
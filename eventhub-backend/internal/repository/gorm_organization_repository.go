@@ -3,6 +3,7 @@ package repository
 import (
 	"crypto/rand"
 	"errors"
+	"eventhub-backend/internal/domain"
 
 	"gorm.io/gorm"
 )
@@ -124,6 +125,7 @@ func (r *GormOrganizationRepository) JoinByCode(userID uint, code string) error
 	orgMember := OrganizationMemberModel{
 		UserID:         userID,
 		OrganizationID: organization.ID,
+		Role:           domain.RoleMember,
 	}
 
 	if err := r.db.Create(&orgMember).Error; err != nil {
@@ -147,22 +149,74 @@ func (r *GormOrganizationRepository) GetCreator(orgID uint) (uint, error) {
 	return organization.FounderID, nil
 }
 
-func (r *GormOrganizationRepository) GetEvents(orgID uint) ([]EventResponse, []EventResponse, error) {
+// GetEvents группирует мероприятия организации по статусам из domain.StatusGroups;
+// черновики и ожидающие публикации попадают в группу unpublished, удаленные - никуда
+func (r *GormOrganizationRepository) GetEvents(orgID uint) (map[string][]EventResponse, error) {
 	var events []EventModel
-	if err := r.db.Where("organization_id = ?", orgID).Find(&events).Error; err != nil {
-		return nil, nil, err
+	if err := r.db.Where("organization_id = ?", orgID).Order("starts_at").Find(&events).Error; err != nil {
+		return nil, err
 	}
 
-	var activeEvents, completedEvents []EventModel
+	grouped := make(map[string][]EventModel, len(domain.StatusGroups)+1)
 	for _, event := range events {
-		if event.Status == "active" {
-			activeEvents = append(activeEvents, event)
-		} else if event.Status == "completed" {
-			completedEvents = append(completedEvents, event)
+		switch {
+		case domain.IsPublished(event.Status):
+			grouped[event.Status] = append(grouped[event.Status], event)
+		case domain.IsUnpublished(event.Status):
+			grouped[EventGroupUnpublished] = append(grouped[EventGroupUnpublished], event)
+		}
+	}
+
+	groups := make(map[string][]EventResponse, len(domain.StatusGroups)+1)
+	for _, group := range domain.StatusGroups {
+		groups[group] = parseEventTime(grouped[group])
+	}
+	groups[EventGroupUnpublished] = parseEventTime(grouped[EventGroupUnpublished])
+
+	return groups, nil
+}
+
+// GetUserEventIDs возвращает мероприятия организации, на которые пользователь
+// идет, и те, где он соорганизатор
+func (r *GormOrganizationRepository) GetUserEventIDs(orgID, userID uint) ([]uint, []uint, error) {
+	var joinedIDs, hostedIDs []uint
+
+	if err := r.db.Table("event_participants").
+		Joins("JOIN events ON events.id = event_participants.event_id").
+		Where("events.organization_id = ? AND event_participants.user_id = ? AND event_participants.status = ?", orgID, userID, domain.RSVPGoing).
+		Pluck("event_participants.event_id", &joinedIDs).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.db.Table("event_hosts").
+		Joins("JOIN events ON events.id = event_hosts.event_id").
+		Where("events.organization_id = ? AND event_hosts.user_id = ?", orgID, userID).
+		Pluck("event_hosts.event_id", &hostedIDs).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return joinedIDs, hostedIDs, nil
+}
+
+// EventGroupUnpublished - группа черновиков, ожидающих одобрения и запланированных мероприятий
+const EventGroupUnpublished = "unpublished"
+
+func (r *GormOrganizationRepository) RequiresApproval(orgID uint) (bool, error) {
+	var organization OrganizationModel
+	if err := r.db.Where("id = ?", orgID).First(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.New("organization not found")
 		}
+		return false, err
 	}
 
-	return parseEventTime(activeEvents), parseEventTime(completedEvents), nil
+	return organization.RequireApproval, nil
+}
+
+func (r *GormOrganizationRepository) UpdateSettings(orgID uint, settings domain.OrganizationSettingsInput) error {
+	return r.db.Model(&OrganizationModel{}).
+		Where("id = ?", orgID).
+		Update("require_approval", settings.RequireApproval).Error
 }
 
 func (r *GormOrganizationRepository) IsOrganizationExist(orgID uint) (bool, error) {
@@ -194,15 +248,19 @@ func (r *GormOrganizationRepository) GetByID(orgID, userID uint) (OrganizationMo
 func (r *GormOrganizationRepository) GetMembers(orgID uint) ([]UserAsMember, error) {
 	var users []UserModel
 	var usersResponse []UserAsMember
-	var userIDs []uint
+	var members []OrganizationMemberModel
 
-	if err := r.db.Table("organization_members").Where("organization_id = ?", orgID).Pluck("user_id", &userIDs).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("участники не найдены")
-		}
+	if err := r.db.Where("organization_id = ?", orgID).Find(&members).Error; err != nil {
 		return nil, err
 	}
 
+	roles := make(map[uint]string, len(members))
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		roles[member.UserID] = member.Role
+		userIDs = append(userIDs, member.UserID)
+	}
+
 	if err := r.db.Table("users").Where("id IN (?)", userIDs).Find(&users).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("участники не найдены")
@@ -216,12 +274,49 @@ func (r *GormOrganizationRepository) GetMembers(orgID uint) ([]UserAsMember, err
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
 			Username:  user.Username,
+			Role:      roles[user.ID],
 		})
 	}
 
 	return usersResponse, nil
 }
 
+// GetRole возвращает роль пользователя в организации; для основателя это owner,
+// для пользователя вне организации - пустая строка
+func (r *GormOrganizationRepository) GetRole(orgID, userID uint) (string, error) {
+	var organization OrganizationModel
+	if err := r.db.Where("id = ?", orgID).First(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("organization not found")
+		}
+		return "", err
+	}
+
+	if organization.FounderID == userID {
+		return domain.RoleOwner, nil
+	}
+
+	var member OrganizationMemberModel
+	if err := r.db.Where("user_id = ? AND organization_id = ?", userID, orgID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if member.Role == "" {
+		return domain.RoleMember, nil
+	}
+
+	return member.Role, nil
+}
+
+func (r *GormOrganizationRepository) SetRole(orgID, userID uint, role string) error {
+	return r.db.Model(&OrganizationMemberModel{}).
+		Where("user_id = ? AND organization_id = ?", userID, orgID).
+		Update("role", role).Error
+}
+
 func (r *GormOrganizationRepository) CheckIfEventExists(orgID, eventID uint) (bool, error) {
 	var event EventModel
 	if err := r.db.Table("events").Where("id = ?", eventID).Where("organization_id = ?", orgID).First(&event).Error; err != nil {
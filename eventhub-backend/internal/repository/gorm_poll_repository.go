@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *GormEventRepository) CreatePoll(poll *EventPollModel, options []EventPollOptionModel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(poll).Error; err != nil {
+			return err
+		}
+
+		for i := range options {
+			options[i].PollID = poll.ID
+		}
+		return tx.Create(&options).Error
+	})
+}
+
+func (r *GormEventRepository) GetPolls(eventID uint) ([]EventPollModel, error) {
+	var polls []EventPollModel
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&polls).Error
+	return polls, err
+}
+
+func (r *GormEventRepository) GetPoll(eventID, pollID uint) (EventPollModel, error) {
+	var poll EventPollModel
+	if err := r.db.Where("id = ? AND event_id = ?", pollID, eventID).First(&poll).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return EventPollModel{}, errors.New("poll not found")
+		}
+		return EventPollModel{}, err
+	}
+
+	return poll, nil
+}
+
+func (r *GormEventRepository) GetPollOptions(pollID uint) ([]EventPollOptionModel, error) {
+	var options []EventPollOptionModel
+	err := r.db.Where("poll_id = ?", pollID).Order("id").Find(&options).Error
+	return options, err
+}
+
+func (r *GormEventRepository) GetPollVotes(pollID uint) ([]EventPollVoteModel, error) {
+	var votes []EventPollVoteModel
+	err := r.db.Where("poll_id = ?", pollID).Order("created_at").Find(&votes).Error
+	return votes, err
+}
+
+// Vote заменяет прежние голоса пользователя в опросе. Опрос блокируется,
+// чтобы голос не попал в уже закрытый опрос.
+func (r *GormEventRepository) Vote(pollID, userID uint, optionIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var poll EventPollModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", pollID).First(&poll).Error; err != nil {
+			return err
+		}
+		if poll.IsClosed(time.Now()) {
+			return errors.New("poll closed")
+		}
+
+		if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&EventPollVoteModel{}).Error; err != nil {
+			return err
+		}
+
+		for _, optionID := range optionIDs {
+			if err := tx.Create(&EventPollVoteModel{PollID: pollID, OptionID: optionID, UserID: userID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClosePoll закрывает опрос под той же блокировкой, что и Vote: пока она
+// держится, голоса не меняются, а параллельное закрытие ждет и получает
+// "poll closed". apply вызывается под блокировкой и возвращает примененный
+// вариант; если он вернул ошибку, опрос остается открытым.
+func (r *GormEventRepository) ClosePoll(pollID uint, apply func(tx *gorm.DB) (*uint, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var poll EventPollModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", pollID).First(&poll).Error; err != nil {
+			return err
+		}
+		if poll.ClosedAt != nil {
+			return errors.New("poll closed")
+		}
+
+		var appliedOptionID *uint
+		if apply != nil {
+			var err error
+			if appliedOptionID, err = apply(tx); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&poll).Updates(map[string]interface{}{"closed_at": time.Now(), "applied_option_id": appliedOptionID}).Error
+	})
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type GormSearchCheckpointRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSearchCheckpointRepository(db *gorm.DB) *GormSearchCheckpointRepository {
+	return &GormSearchCheckpointRepository{db: db}
+}
+
+func (r *GormSearchCheckpointRepository) Get(name string) (SearchCheckpointModel, error) {
+	var checkpoint SearchCheckpointModel
+	if err := r.db.Where("name = ?", name).First(&checkpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return SearchCheckpointModel{Name: name}, nil
+		}
+		return SearchCheckpointModel{}, err
+	}
+
+	return checkpoint, nil
+}
+
+func (r *GormSearchCheckpointRepository) Save(checkpoint SearchCheckpointModel) error {
+	return r.db.Save(&checkpoint).Error
+}
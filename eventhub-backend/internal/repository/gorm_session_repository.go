@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepository(db *gorm.DB) *GormSessionRepository {
+	return &GormSessionRepository{db: db}
+}
+
+func (r *GormSessionRepository) Create(session *SessionModel) error {
+	return r.db.Create(session).Error
+}
+
+func (r *GormSessionRepository) GetByJTI(jti string) (SessionModel, error) {
+	var session SessionModel
+	if err := r.db.Where("jti = ?", jti).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return SessionModel{}, errors.New("session not found")
+		}
+		return SessionModel{}, err
+	}
+
+	return session, nil
+}
+
+// Rotate помечает старую сессию использованной и создает следующую в той же семье.
+// Возвращает false, если старая сессия уже была использована или отозвана.
+func (r *GormSessionRepository) Rotate(oldJTI string, next *SessionModel) (bool, error) {
+	rotated := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&SessionModel{}).
+			Where("jti = ? AND rotated_at IS NULL AND revoked_at IS NULL", oldJTI).
+			Update("rotated_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rotated, nil
+}
+
+func (r *GormSessionRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&SessionModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *GormSessionRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&SessionModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+func (r *GormEventRepository) CreateTemplate(template *EventTemplateModel) error {
+	return r.db.Create(template).Error
+}
+
+func (r *GormEventRepository) GetTemplates(orgID uint) ([]EventTemplateModel, error) {
+	templates := []EventTemplateModel{}
+	err := r.db.Where("organization_id = ?", orgID).Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+func (r *GormEventRepository) GetTemplate(orgID, templateID uint) (EventTemplateModel, error) {
+	var template EventTemplateModel
+	if err := r.db.Where("id = ? AND organization_id = ?", templateID, orgID).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return EventTemplateModel{}, errors.New("template not found")
+		}
+		return EventTemplateModel{}, err
+	}
+
+	return template, nil
+}
+
+func (r *GormEventRepository) DeleteTemplate(templateID uint) error {
+	return r.db.Delete(&EventTemplateModel{}, templateID).Error
+}
+
+// GetEventFiles возвращает обложку и файлы мероприятия
+func (r *GormEventRepository) GetEventFiles(eventID uint) ([]EventAttachmentModel, error) {
+	var attachments []EventAttachmentModel
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+// GetTicketTypeModels возвращает типы билетов без подсчета оставшихся мест
+func (r *GormEventRepository) GetTicketTypeModels(eventID uint) ([]EventTicketTypeModel, error) {
+	var ticketTypes []EventTicketTypeModel
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&ticketTypes).Error
+	return ticketTypes, err
+}
+
+// CreateCopy создает мероприятие вместе с анкетой и типами билетов. Файлы
+// копируются в хранилище уже под новым ID и добавляются через AddFiles.
+func (r *GormEventRepository) CreateCopy(input domain.CreateEventInput, creatorID, orgID uint, status string, extras EventCopy) (*EventModel, error) {
+	event := newEventModel(input, creatorID, orgID, status)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+
+		for i, field := range extras.FormFields {
+			field.ID = 0
+			field.EventID = event.ID
+			field.Position = i
+			if err := tx.Create(&field).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, ticketType := range extras.TicketTypes {
+			ticketType.ID = 0
+			ticketType.EventID = event.ID
+			if err := tx.Create(&ticketType).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// AddFiles сохраняет записи о скопированных файлах; обложка сразу
+// проставляется в мероприятие
+func (r *GormEventRepository) AddFiles(eventID uint, attachments []EventAttachmentModel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range attachments {
+			attachments[i].ID = 0
+			attachments[i].EventID = eventID
+			if err := tx.Create(&attachments[i]).Error; err != nil {
+				return err
+			}
+
+			if attachments[i].Kind == AttachmentCover {
+				urls := map[string]interface{}{"cover_url": attachments[i].URL, "cover_thumb_url": attachments[i].ThumbURL}
+				if err := tx.Model(&EventModel{}).Where("id = ?", eventID).Updates(urls).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *GormEventRepository) CreateTicketType(ticketType *EventTicketTypeModel) error {
+	return r.db.Create(ticketType).Error
+}
+
+// GetTicketTypes возвращает типы билетов мероприятия с числом оставшихся мест
+func (r *GormEventRepository) GetTicketTypes(eventID uint) ([]TicketTypeAvailability, error) {
+	var ticketTypes []EventTicketTypeModel
+	if err := r.db.Where("event_id = ?", eventID).Order("id").Find(&ticketTypes).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]TicketTypeAvailability, 0, len(ticketTypes))
+	for _, ticketType := range ticketTypes {
+		remaining, err := remainingTickets(r.db, ticketType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, TicketTypeAvailability{EventTicketTypeModel: ticketType, Remaining: remaining})
+	}
+
+	return result, nil
+}
+
+func (r *GormEventRepository) GetTicketType(eventID, ticketTypeID uint) (EventTicketTypeModel, error) {
+	var ticketType EventTicketTypeModel
+	err := r.db.Where("id = ? AND event_id = ?", ticketTypeID, eventID).First(&ticketType).Error
+	return ticketType, err
+}
+
+func (r *GormEventRepository) HasTicketTypes(eventID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&EventTicketTypeModel{}).Where("event_id = ?", eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteTicketType удаляет тип, только если по нему еще не выдано ни одного билета
+func (r *GormEventRepository) DeleteTicketType(eventID, ticketTypeID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var issued int64
+		if err := tx.Model(&EventTicketModel{}).Where("ticket_type_id = ?", ticketTypeID).Count(&issued).Error; err != nil {
+			return err
+		}
+		if issued == 0 {
+			if err := tx.Model(&EventWaitlistModel{}).Where("ticket_type_id = ?", ticketTypeID).Count(&issued).Error; err != nil {
+				return err
+			}
+		}
+		if issued > 0 {
+			return errors.New("ticket type in use")
+		}
+
+		result := tx.Where("id = ? AND event_id = ?", ticketTypeID, eventID).Delete(&EventTicketTypeModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("ticket type not found")
+		}
+		return nil
+	})
+}
+
+// GetTicket возвращает действующий билет участника. Участникам, записанным до
+// появления билетов или через RSVP, билет без типа выдается при первом запросе.
+func (r *GormEventRepository) GetTicket(userID, eventID uint) (EventTicketModel, error) {
+	var ticket EventTicketModel
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var participants int64
+		if err := tx.Model(&EventParticipantModel{}).
+			Where("user_id = ? AND event_id = ? AND status = ?", userID, eventID, domain.RSVPGoing).
+			Count(&participants).Error; err != nil {
+			return err
+		}
+		if participants == 0 {
+			return errors.New("user not joined")
+		}
+
+		var err error
+		ticket, err = issueTicket(tx, userID, eventID, nil)
+		return err
+	})
+	if err != nil {
+		return EventTicketModel{}, err
+	}
+
+	return ticket, nil
+}
+
+func (r *GormEventRepository) GetTicketByID(ticketID uint) (EventTicketModel, error) {
+	var ticket EventTicketModel
+	err := r.db.First(&ticket, ticketID).Error
+	return ticket, err
+}
+
+// GetRevokedTicketIDs возвращает билеты мероприятия, отозванные после since
+func (r *GormEventRepository) GetRevokedTicketIDs(eventID uint, since time.Time) ([]uint, error) {
+	ticketIDs := []uint{}
+	err := r.db.Model(&EventTicketModel{}).
+		Where("event_id = ? AND revoked_at > ?", eventID, since).
+		Order("id").
+		Pluck("id", &ticketIDs).Error
+	return ticketIDs, err
+}
+
+// issueTicket выдает билет, если у пользователя еще нет действующего
+func issueTicket(tx *gorm.DB, userID, eventID uint, ticketTypeID *uint) (EventTicketModel, error) {
+	var ticket EventTicketModel
+	err := tx.Where("user_id = ? AND event_id = ? AND revoked_at IS NULL", userID, eventID).First(&ticket).Error
+	if err == nil {
+		return ticket, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return EventTicketModel{}, err
+	}
+
+	ticket = EventTicketModel{UserID: userID, EventID: eventID, TicketTypeID: ticketTypeID}
+	if err := tx.Create(&ticket).Error; err != nil {
+		return EventTicketModel{}, err
+	}
+
+	return ticket, nil
+}
+
+func revokeTickets(tx *gorm.DB, userID, eventID uint) error {
+	return tx.Model(&EventTicketModel{}).
+		Where("user_id = ? AND event_id = ? AND revoked_at IS NULL", userID, eventID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// reserveTicketType блокирует тип билета и проверяет, что квота не исчерпана
+func reserveTicketType(tx *gorm.DB, eventID, ticketTypeID uint) error {
+	var ticketType EventTicketTypeModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND event_id = ?", ticketTypeID, eventID).
+		First(&ticketType).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("ticket type not found")
+		}
+		return err
+	}
+
+	remaining, err := remainingTickets(tx, ticketType)
+	if err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return errors.New("ticket type sold out")
+	}
+
+	return nil
+}
+
+// remainingTickets считает действующие билеты и места в очереди; -1 - без ограничений
+func remainingTickets(tx *gorm.DB, ticketType EventTicketTypeModel) (int, error) {
+	if ticketType.Quota == nil {
+		return -1, nil
+	}
+
+	var issued, waitlisted int64
+	if err := tx.Model(&EventTicketModel{}).Where("ticket_type_id = ? AND revoked_at IS NULL", ticketType.ID).Count(&issued).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&EventWaitlistModel{}).Where("ticket_type_id = ?", ticketType.ID).Count(&waitlisted).Error; err != nil {
+		return 0, err
+	}
+
+	return max(*ticketType.Quota-int(issued+waitlisted), 0), nil
+}
@@ -3,11 +3,13 @@ package repository
 import "time"
 
 type NotificationModel struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `json:"user_id"`
-	EventID   uint      `json:"event_id"`
-	Type      string    `json:"type"`
-	CreatedAt time.Time `json:"created_at"`
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	UserID  uint   `json:"user_id"`
+	EventID uint   `json:"event_id"`
+	Type    string `json:"type"`
+	// правка, о которой уведомление reschedule; у старых уведомлений ее нет
+	RevisionID *uint     `json:"revision_id"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 func (NotificationModel) TableName() string {
@@ -5,6 +5,8 @@ type OrganizationModel struct {
 	Name       string `gorm:"unique" json:"name"`
 	FounderID  uint   `json:"founder_id"`
 	InviteCode string `gorm:"unique" json:"invite_code"`
+	// RequireApproval - публикация мероприятий через одобрение администратора
+	RequireApproval bool `gorm:"default:false" json:"require_approval"`
 }
 
 func (OrganizationModel) TableName() string {
@@ -12,8 +14,9 @@ func (OrganizationModel) TableName() string {
 }
 
 type OrganizationMemberModel struct {
-	UserID         uint `json:"user_id"`
-	OrganizationID uint `json:"organization_id"`
+	UserID         uint   `json:"user_id"`
+	OrganizationID uint   `json:"organization_id"`
+	Role           string `gorm:"default:member" json:"role"`
 }
 
 func (OrganizationMemberModel) TableName() string {
@@ -0,0 +1,50 @@
+package repository
+
+import "time"
+
+// EventPollModel - опрос участников мероприятия. Опрос закрыт, если задан
+// ClosedAt или наступил ClosesAt.
+type EventPollModel struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	EventID         uint       `gorm:"index" json:"event_id"`
+	CreatorID       uint       `json:"creator_id"`
+	Question        string     `json:"question"`
+	Kind            string     `gorm:"default:single" json:"kind"`
+	Anonymous       bool       `json:"anonymous"`
+	ClosesAt        *time.Time `gorm:"type:timestamptz" json:"closes_at"`
+	ClosedAt        *time.Time `gorm:"type:timestamptz" json:"closed_at"`
+	AppliedOptionID *uint      `json:"applied_option_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (EventPollModel) TableName() string {
+	return "event_polls"
+}
+
+func (p EventPollModel) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+type EventPollOptionModel struct {
+	ID       uint       `gorm:"primaryKey" json:"id"`
+	PollID   uint       `gorm:"index" json:"poll_id"`
+	Text     string     `json:"text"`
+	StartsAt *time.Time `gorm:"type:timestamptz" json:"starts_at"`
+	EndsAt   *time.Time `gorm:"type:timestamptz" json:"ends_at"`
+	Location string     `json:"location"`
+}
+
+func (EventPollOptionModel) TableName() string {
+	return "event_poll_options"
+}
+
+type EventPollVoteModel struct {
+	PollID    uint      `gorm:"primaryKey" json:"poll_id"`
+	OptionID  uint      `gorm:"primaryKey" json:"option_id"`
+	UserID    uint      `gorm:"primaryKey;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EventPollVoteModel) TableName() string {
+	return "event_poll_votes"
+}
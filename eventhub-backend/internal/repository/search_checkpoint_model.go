@@ -0,0 +1,14 @@
+package repository
+
+import "time"
+
+// SearchCheckpointModel запоминает, до какого изменения индекс уже обновлен
+type SearchCheckpointModel struct {
+	Name      string    `gorm:"primaryKey" json:"name"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:false" json:"updated_at"`
+	LastID    uint      `json:"last_id"`
+}
+
+func (SearchCheckpointModel) TableName() string {
+	return "search_checkpoints"
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "time"
+
+type SessionModel struct {
+	JTI       string     `gorm:"primaryKey" json:"jti"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	FamilyID  string     `gorm:"index" json:"family_id"`
+	UserAgent string     `json:"user_agent"`
+	IP        string     `json:"ip"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (SessionModel) TableName() string {
+	return "sessions"
+}
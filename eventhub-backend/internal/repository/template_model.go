@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"eventhub-backend/internal/domain"
+	"time"
+)
+
+// EventTemplateModel - именованный шаблон мероприятия организации. Анкета,
+// типы билетов и файлы хранятся копией, чтобы правка или удаление исходного
+// мероприятия не меняли шаблон. Окна продаж билетов привязаны к датам
+// мероприятия и в шаблон не попадают.
+type EventTemplateModel struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	OrganizationID uint   `gorm:"index" json:"organization_id"`
+	Name           string `json:"name"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	Category       string `json:"category"`
+	Location       string `json:"location"`
+	IsPublic       bool   `json:"is_public"`
+	Timezone       string `json:"timezone"`
+	Capacity       *int   `json:"capacity"`
+	// длительность в секундах
+	Duration    int64                    `json:"duration"`
+	FormFields  []domain.FormFieldInput  `gorm:"type:jsonb;serializer:json" json:"form_fields"`
+	TicketTypes []domain.TicketTypeInput `gorm:"type:jsonb;serializer:json" json:"ticket_types"`
+	Attachments []TemplateAttachment     `gorm:"type:jsonb;serializer:json" json:"attachments"`
+	CreatedBy   uint                     `json:"created_by"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+func (EventTemplateModel) TableName() string {
+	return "event_templates"
+}
+
+// TemplateAttachment - копия обложки или файла в хранилище, принадлежащая шаблону
+type TemplateAttachment struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ThumbKey    string `json:"thumb_key,omitempty"`
+	ThumbURL    string `json:"thumb_url,omitempty"`
+}
+
+// EventCopy - то, что переносится в новое мероприятие при дублировании или
+// из шаблона. Своих настроек напоминаний у мероприятия нет, они общие для
+// всего сервера, поэтому копировать их не нужно.
+type EventCopy struct {
+	FormFields  []EventFormFieldModel
+	TicketTypes []EventTicketTypeModel
+}
@@ -0,0 +1,38 @@
+package repository
+
+import "time"
+
+// EventTicketTypeModel - тип билета на мероприятие со своей квотой и окном продаж
+type EventTicketTypeModel struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	EventID    uint       `gorm:"index" json:"event_id"`
+	Name       string     `json:"name"`
+	Quota      *int       `json:"quota"` // nil - без ограничений
+	SalesStart *time.Time `gorm:"type:timestamptz" json:"sales_start"`
+	SalesEnd   *time.Time `gorm:"type:timestamptz" json:"sales_end"`
+}
+
+func (EventTicketTypeModel) TableName() string {
+	return "event_ticket_types"
+}
+
+// EventTicketModel - выданный билет. Отмененный билет не удаляется, а получает
+// RevokedAt, чтобы сканеры могли забрать его через список отозванных.
+type EventTicketModel struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	EventID      uint       `gorm:"index:idx_ticket_event_user" json:"event_id"`
+	UserID       uint       `gorm:"index:idx_ticket_event_user" json:"user_id"`
+	TicketTypeID *uint      `gorm:"index" json:"ticket_type_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `gorm:"index" json:"revoked_at"`
+}
+
+func (EventTicketModel) TableName() string {
+	return "event_tickets"
+}
+
+// TicketTypeAvailability - тип билета с числом оставшихся мест (-1 - без ограничений)
+type TicketTypeAvailability struct {
+	EventTicketTypeModel
+	Remaining int `json:"remaining"`
+}
@@ -26,4 +26,5 @@ type UserAsMember struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Username  string `json:"username"`
+	Role      string `json:"role"`
 }
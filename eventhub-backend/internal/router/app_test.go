@@ -0,0 +1,193 @@
+package router
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"eventhub-backend/internal/config"
+	"eventhub-backend/internal/database"
+	"eventhub-backend/internal/handlers"
+	"eventhub-backend/internal/middleware"
+	"eventhub-backend/internal/repository"
+	"eventhub-backend/internal/search"
+	"eventhub-backend/internal/service"
+	"eventhub-backend/internal/storage"
+	customJwt "eventhub-backend/pkg/jwt"
+	"eventhub-backend/pkg/ticket"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// testApp - приложение целиком, как в cmd/main.go: маршруты, middleware,
+// сервисы и репозитории поверх тестовой базы из database.OpenTest
+type testApp struct {
+	t       *testing.T
+	db      *gorm.DB
+	e       *echo.Echo
+	jwt     customJwt.Manager
+	tickets *ticket.Signer
+}
+
+func newTestApp(t *testing.T) *testApp {
+	db := database.OpenTest(t)
+
+	jwtManager := customJwt.NewJwtManager("test-secret")
+	_, ticketKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticketSigner, err := ticket.NewSigner("test", ticketKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobStore := storage.NewLocalStore(t.TempDir(), "http://localhost:3000/files")
+
+	userRepo := repository.NewGormUserRepository(db)
+	eventRepo := repository.NewGormEventRepository(db)
+	organizationRepo := repository.NewGormOrganizationRepository(db)
+	notificationRepo := repository.NewGormNotificationRepository(db)
+	sessionRepo := repository.NewGormSessionRepository(db)
+	checkpointRepo := repository.NewGormSearchCheckpointRepository(db)
+
+	authService := service.NewAuthService(config.Config{}, *userRepo, *sessionRepo, jwtManager)
+	registerService := service.NewRegisterService(*userRepo)
+	userService := service.NewUserService(*userRepo)
+	eventService := service.NewEventService(*eventRepo, *organizationRepo, *checkpointRepo, search.NewPostgresSearcher(db), ticketSigner, blobStore)
+	organizationService := service.NewOrganizationService(*organizationRepo)
+	notificationService := service.NewNotificationService(*notificationRepo, *eventRepo, true)
+
+	e := echo.New()
+	Register(e, Handlers{
+		Auth:         handlers.NewAuthHandler(authService, registerService),
+		Event:        handlers.NewEventHandler(eventService, userService, notificationService),
+		User:         handlers.NewUserHandler(userService),
+		Organization: handlers.NewOrganizationHandler(organizationService),
+		Notification: handlers.NewNotificationHandler(notificationService),
+		JWKS:         handlers.NewJWKSHandler(jwtManager),
+	}, middleware.NewMiddleware(jwtManager), organizationService)
+
+	return &testApp{t: t, db: db, e: e, jwt: jwtManager, tickets: ticketSigner}
+}
+
+// do выполняет запрос с телом в JSON от имени пользователя; userID == 0 - без токена
+func (a *testApp) do(userID uint, method, path string, body interface{}) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	return a.send(userID, method, path, echo.MIMEApplicationJSON, reader)
+}
+
+func (a *testApp) send(userID uint, method, path, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	if userID != 0 {
+		token, err := a.jwt.GenerateAccessToken(userID)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (a *testApp) create(value interface{}) {
+	a.t.Helper()
+	if err := a.db.Create(value).Error; err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+func (a *testApp) user(name string) uint {
+	a.t.Helper()
+	user := repository.UserModel{FirstName: name, Username: fmt.Sprintf("%s-%d", name, time.Now().UnixNano())}
+	a.create(&user)
+	return user.ID
+}
+
+func (a *testApp) organization(founderID uint) uint {
+	a.t.Helper()
+	org := repository.OrganizationModel{
+		Name:       fmt.Sprintf("org-%d", time.Now().UnixNano()),
+		FounderID:  founderID,
+		InviteCode: fmt.Sprintf("code-%d", time.Now().UnixNano()),
+	}
+	a.create(&org)
+	return org.ID
+}
+
+func (a *testApp) member(orgID, userID uint, role string) {
+	a.t.Helper()
+	a.create(&repository.OrganizationMemberModel{OrganizationID: orgID, UserID: userID, Role: role})
+}
+
+func (a *testApp) event(orgID, creatorID uint, public bool, status string) repository.EventModel {
+	a.t.Helper()
+	startsAt := time.Now().Add(72 * time.Hour).Truncate(time.Second).UTC()
+	event := repository.EventModel{
+		Title:          "Встреча",
+		Status:         status,
+		IsPublic:       public,
+		StartsAt:       startsAt,
+		EndsAt:         startsAt.Add(2 * time.Hour),
+		Timezone:       "UTC",
+		Location:       "Зал 1",
+		CreatorId:      creatorID,
+		OrganizationId: orgID,
+	}
+	a.create(&event)
+	return event
+}
+
+func (a *testApp) host(eventID, userID uint) {
+	a.t.Helper()
+	a.create(&repository.EventHostModel{EventID: eventID, UserID: userID})
+}
+
+func (a *testApp) participant(eventID, userID uint, status string) {
+	a.t.Helper()
+	now := time.Now()
+	a.create(&repository.EventParticipantModel{EventID: eventID, UserID: userID, Status: status, RespondedAt: &now})
+}
+
+func (a *testApp) reload(event *repository.EventModel) {
+	a.t.Helper()
+	if err := a.db.First(event, event.ID).Error; err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+// updateInput - тело PUT .../update с теми же полями, что у мероприятия
+func updateInput(event repository.EventModel) map[string]interface{} {
+	return map[string]interface{}{
+		"title":     event.Title,
+		"location":  event.Location,
+		"is_public": event.IsPublic,
+		"starts_at": event.StartsAt.Format(time.RFC3339),
+		"ends_at":   event.EndsAt.Format(time.RFC3339),
+		"timezone":  event.Timezone,
+		"capacity":  event.Capacity,
+	}
+}
+
+func updatePath(event repository.EventModel) string {
+	return fmt.Sprintf("/api/organizations/%d/events/%d/update", event.OrganizationId, event.ID)
+}
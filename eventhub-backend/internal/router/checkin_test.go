@@ -0,0 +1,101 @@
+package router
+
+import (
+	"encoding/json"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"eventhub-backend/pkg/ticket"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// signedTicket выдает билет участнику в обход API, со сроком expiresAt
+func (a *testApp) signedTicket(eventID, userID uint, expiresAt time.Time) string {
+	a.t.Helper()
+	issued := repository.EventTicketModel{EventID: eventID, UserID: userID}
+	a.create(&issued)
+	return a.tickets.Sign(ticket.Ticket{ID: issued.ID, EventID: eventID, UserID: userID, ExpiresAt: expiresAt})
+}
+
+// Билет, выданный до переноса, действует до нового окончания мероприятия
+func TestCheckInAfterReschedule(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	guest := app.user("guest")
+	orgID := app.organization(founder)
+
+	event := app.event(orgID, founder, true, domain.EventStatusActive)
+	app.participant(event.ID, guest, domain.RSVPGoing)
+	// срок, подписанный по старому расписанию, уже прошел
+	token := app.signedTicket(event.ID, guest, time.Now().Add(-time.Hour))
+
+	path := fmt.Sprintf("/api/events/%d/checkin", event.ID)
+	if rec := app.do(founder, http.MethodPost, path, map[string]string{"token": token}); rec.Code != http.StatusOK {
+		t.Fatalf("отметка по билету перенесенного мероприятия: статус %d, %s", rec.Code, rec.Body)
+	}
+}
+
+// Билет закончившегося мероприятия не принимается, даже если подписан с запасом
+func TestCheckInAfterEventEnded(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	guest := app.user("guest")
+	orgID := app.organization(founder)
+
+	event := app.event(orgID, founder, true, domain.EventStatusActive)
+	app.participant(event.ID, guest, domain.RSVPGoing)
+	token := app.signedTicket(event.ID, guest, event.EndsAt.Add(2*time.Hour))
+
+	// мероприятие перенесли на более раннее время, и оно уже прошло
+	if err := app.db.Model(&event).Updates(map[string]interface{}{
+		"starts_at": time.Now().Add(-8 * time.Hour),
+		"ends_at":   time.Now().Add(-6 * time.Hour),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	path := fmt.Sprintf("/api/events/%d/checkin", event.ID)
+	if rec := app.do(founder, http.MethodPost, path, map[string]string{"token": token}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("ожидался отказ, статус %d, %s", rec.Code, rec.Body)
+	}
+}
+
+// Отмененное мероприятие попадает в список отзыва для сканеров, а билеты на него не принимаются
+func TestCancelledEventRevoked(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	guest := app.user("guest")
+	orgID := app.organization(founder)
+
+	event := app.event(orgID, founder, true, domain.EventStatusCancelled)
+	app.participant(event.ID, guest, domain.RSVPGoing)
+	token := app.signedTicket(event.ID, guest, event.EndsAt.Add(2*time.Hour))
+
+	rec := app.do(founder, http.MethodGet, fmt.Sprintf("/api/events/%d/tickets/revoked", event.ID), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("синхронизация: статус %d, %s", rec.Code, rec.Body)
+	}
+	var sync struct {
+		RevokedEvents []uint    `json:"revoked_events"`
+		ExpiresAt     time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &sync); err != nil {
+		t.Fatal(err)
+	}
+	if len(sync.RevokedEvents) != 1 || sync.RevokedEvents[0] != event.ID {
+		t.Fatalf("отмененное мероприятие не отозвано: %v", sync.RevokedEvents)
+	}
+	if !sync.ExpiresAt.Equal(event.EndsAt.Add(2 * time.Hour)) {
+		t.Fatalf("срок действия %v, ожидалось %v", sync.ExpiresAt, event.EndsAt.Add(2*time.Hour))
+	}
+
+	path := fmt.Sprintf("/api/events/%d/checkin", event.ID)
+	if rec := app.do(founder, http.MethodPost, path, map[string]string{"token": token}); rec.Code != http.StatusGone {
+		t.Fatalf("ожидался отказ, статус %d, %s", rec.Code, rec.Body)
+	}
+}
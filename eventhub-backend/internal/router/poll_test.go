@@ -0,0 +1,80 @@
+package router
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// Перенос по итогам опроса уведомляет участника один раз - правкой со ссылкой на нее
+func TestClosePollNotifiesOnce(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	participant := app.user("participant")
+	orgID := app.organization(founder)
+	app.member(orgID, participant, domain.RoleMember)
+
+	event := app.event(orgID, founder, true, domain.EventStatusActive)
+	app.participant(event.ID, participant, domain.RSVPGoing)
+
+	poll := repository.EventPollModel{EventID: event.ID, CreatorID: founder, Question: "Где собираемся?"}
+	app.create(&poll)
+	option := repository.EventPollOptionModel{PollID: poll.ID, Text: "Зал 2", Location: "Зал 2"}
+	app.create(&option)
+	app.create(&repository.EventPollVoteModel{PollID: poll.ID, OptionID: option.ID, UserID: participant})
+
+	path := fmt.Sprintf("/api/events/%d/polls/%d/close", event.ID, poll.ID)
+	if rec := app.do(founder, http.MethodPost, path, map[string]bool{"apply": true}); rec.Code != http.StatusOK {
+		t.Fatalf("закрытие опроса: статус %d, %s", rec.Code, rec.Body)
+	}
+
+	app.reload(&event)
+	if event.Location != "Зал 2" {
+		t.Fatalf("место не перенесено: %q", event.Location)
+	}
+	if err := app.db.First(&poll, poll.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if poll.ClosedAt == nil || poll.AppliedOptionID == nil || *poll.AppliedOptionID != option.ID {
+		t.Fatalf("опрос не закрыт вместе с переносом: %+v", poll)
+	}
+
+	var notifications []repository.NotificationModel
+	if err := app.db.Where("user_id = ? AND event_id = ?", participant, event.ID).Find(&notifications).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("участник получил %d уведомлений, ожидалось одно", len(notifications))
+	}
+	if notifications[0].Type != "reschedule" || notifications[0].RevisionID == nil {
+		t.Fatalf("уведомление без правки: %+v", notifications[0])
+	}
+}
+
+// Возобновление не меняет время и место и поэтому не уведомляет повторно
+func TestResumeDoesNotNotify(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	participant := app.user("participant")
+	orgID := app.organization(founder)
+
+	event := app.event(orgID, founder, true, domain.EventStatusPostponed)
+	app.participant(event.ID, participant, domain.RSVPGoing)
+
+	path := fmt.Sprintf("/api/events/%d/resume", event.ID)
+	if rec := app.do(founder, http.MethodPost, path, nil); rec.Code != http.StatusOK {
+		t.Fatalf("возобновление: статус %d, %s", rec.Code, rec.Body)
+	}
+
+	var count int64
+	if err := app.db.Model(&repository.NotificationModel{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("создано %d уведомлений", count)
+	}
+}
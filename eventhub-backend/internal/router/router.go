@@ -0,0 +1,125 @@
+package router
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/handlers"
+	"eventhub-backend/internal/middleware"
+	"eventhub-backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handlers struct {
+	Auth         *handlers.AuthHandler
+	Event        *handlers.EventHandler
+	User         *handlers.UserHandler
+	Organization *handlers.OrganizationHandler
+	Notification *handlers.NotificationHandler
+	JWKS         *handlers.JWKSHandler
+}
+
+// Register регистрирует маршруты API; тесты собирают приложение через него же
+func Register(e *echo.Echo, h Handlers, authMW *middleware.Middleware, organizationService *service.OrganizationService) {
+	e.GET("/.well-known/jwks.json", h.JWKS.GetKeys)            // GET /.well-known/jwks.json
+	e.GET("/.well-known/ticket-keys.json", h.Event.TicketKeys) // GET /.well-known/ticket-keys.json
+
+	api := e.Group("/api")
+
+	// public
+	api.POST("/refresh", h.Auth.Refresh)   // POST /api/refresh
+	api.POST("/register", h.Auth.Register) // POST /api/register
+	api.POST("/login", h.Auth.Login)       // POST /api/login
+	api.POST("/logout", h.Auth.Logout)     // POST /api/logout
+
+	// authorized
+	auth := api.Group("", authMW.AuthRequired)
+	auth.POST("/logout-all", h.Auth.LogoutAll) // POST /api/logout-all
+
+	// -- events --
+	events := auth.Group("/events")
+	events.GET("", h.Event.GetAllUser)                                         // GET /api/events
+	events.GET("/search", h.Event.Search)                                      // GET /api/events/search
+	events.GET("/:id", h.Event.GetByID)                                        // GET /api/events/:id
+	events.GET("/:id/participants", h.Event.GetParticipants)                   // GET /api/events/:id/participants
+	events.POST("/:id/join", h.Event.Join)                                     // POST   /api/events/:id/join
+	events.DELETE("/:id/quit", h.Event.Quit)                                   // DELETE /api/events/:id/quit
+	events.PUT("/:id/rsvp", h.Event.SetRSVP)                                   // PUT    /api/events/:id/rsvp
+	events.GET("/:id/ticket", h.Event.Ticket)                                  // GET    /api/events/:id/ticket
+	events.POST("/:id/checkin", h.Event.CheckIn)                               // POST   /api/events/:id/checkin
+	events.GET("/:id/attendance", h.Event.Attendance)                          // GET    /api/events/:id/attendance
+	events.GET("/:id/tickets/revoked", h.Event.RevokedTickets)                 // GET    /api/events/:id/tickets/revoked
+	events.GET("/:id/ticket-types", h.Event.GetTicketTypes)                    // GET    /api/events/:id/ticket-types
+	events.POST("/:id/ticket-types", h.Event.CreateTicketType)                 // POST   /api/events/:id/ticket-types
+	events.DELETE("/:id/ticket-types/:type_id", h.Event.DeleteTicketType)      // DELETE /api/events/:id/ticket-types/:type_id
+	events.GET("/:id/hosts", h.Event.GetHosts)                                 // GET    /api/events/:id/hosts
+	events.POST("/:id/hosts", h.Event.AddHost)                                 // POST   /api/events/:id/hosts
+	events.DELETE("/:id/hosts/:user_id", h.Event.RemoveHost)                   // DELETE /api/events/:id/hosts/:user_id
+	events.GET("/:id/comments", h.Event.GetComments)                           // GET    /api/events/:id/comments
+	events.POST("/:id/comments", h.Event.CreateComment)                        // POST   /api/events/:id/comments
+	events.PATCH("/:id/comments/:comment_id", h.Event.UpdateComment)           // PATCH  /api/events/:id/comments/:comment_id
+	events.DELETE("/:id/comments/:comment_id", h.Event.DeleteComment)          // DELETE /api/events/:id/comments/:comment_id
+	events.GET("/:id/polls", h.Event.GetPolls)                                 // GET    /api/events/:id/polls
+	events.POST("/:id/polls", h.Event.CreatePoll)                              // POST   /api/events/:id/polls
+	events.GET("/:id/polls/:poll_id", h.Event.GetPoll)                         // GET    /api/events/:id/polls/:poll_id
+	events.PUT("/:id/polls/:poll_id/vote", h.Event.Vote)                       // PUT    /api/events/:id/polls/:poll_id/vote
+	events.POST("/:id/polls/:poll_id/close", h.Event.ClosePoll)                // POST   /api/events/:id/polls/:poll_id/close
+	events.POST("/:id/feedback", h.Event.SubmitFeedback)                       // POST   /api/events/:id/feedback
+	events.GET("/:id/feedback", h.Event.GetFeedback)                           // GET    /api/events/:id/feedback
+	events.PUT("/:id/cover", h.Event.UploadCover)                              // PUT    /api/events/:id/cover
+	events.DELETE("/:id/cover", h.Event.DeleteCover)                           // DELETE /api/events/:id/cover
+	events.POST("/:id/attachments", h.Event.UploadAttachment)                  // POST   /api/events/:id/attachments
+	events.DELETE("/:id/attachments/:attachment_id", h.Event.DeleteAttachment) // DELETE /api/events/:id/attachments/:attachment_id
+	events.GET("/:id/form", h.Event.GetForm)                                   // GET    /api/events/:id/form
+	events.PUT("/:id/form", h.Event.UpdateForm)                                // PUT    /api/events/:id/form
+	events.GET("/:id/form/answers", h.Event.GetFormAnswers)                    // GET    /api/events/:id/form/answers
+	events.POST("/:id/publish", h.Event.Publish)                               // POST   /api/events/:id/publish
+	events.POST("/:id/unpublish", h.Event.Unpublish)                           // POST   /api/events/:id/unpublish
+	events.POST("/:id/approve", h.Event.Approve)                               // POST   /api/events/:id/approve
+	events.POST("/:id/reject", h.Event.Reject)                                 // POST   /api/events/:id/reject
+	events.POST("/:id/cancel", h.Event.Cancel)                                 // POST   /api/events/:id/cancel
+	events.POST("/:id/postpone", h.Event.Postpone)                             // POST   /api/events/:id/postpone
+	events.POST("/:id/resume", h.Event.Resume)                                 // POST   /api/events/:id/resume
+	events.GET("/:id/status-history", h.Event.StatusHistory)                   // GET    /api/events/:id/status-history
+	events.GET("/:id/history", h.Event.History)                                // GET    /api/events/:id/history
+	events.POST("/:id/duplicate", h.Event.Duplicate)                           // POST   /api/events/:id/duplicate
+	events.DELETE("/:id/delete", h.Event.Delete)                               // DELETE /api/events/:id/delete
+
+	// -- organizations --
+	organizations := auth.Group("/organizations")
+	organizations.GET("", h.Organization.GetAll)                 // GET /api/organizations
+	organizations.GET("/:id/events", h.Organization.GetEvents)   // GET /api/organizations/:id/events
+	organizations.GET("/:id", h.Organization.GetByID)            // GET /api/organizations/:id
+	organizations.GET("/:id/members", h.Organization.GetMembers) // GET /api/organizations/:id/members
+	organizations.POST("", h.Organization.Create)                // POST /api/organizations/
+	organizations.POST("/join/:code", h.Organization.JoinByCode) // POST /api/organizations/join/:code
+
+	// правку разрешает policy.CanEditEvent в сервисе: соорганизатору с ролью
+	// member права организации на создание мероприятий не нужны
+	organizations.PUT("/:id/events/:event_id/update", h.Event.Update) // PUT /api/organizations/:id/events/:event_id/update
+
+	// -- organizations (by role) --
+	orgEvents := organizations.Group("/:id", authMW.RequireOrgPermission(organizationService, domain.PermCreateEvents))
+	orgEvents.POST("/events", h.Event.Create)                           // POST   /api/organizations/:id/events
+	orgEvents.GET("/templates", h.Event.GetTemplates)                   // GET    /api/organizations/:id/templates
+	orgEvents.POST("/templates", h.Event.CreateTemplate)                // POST   /api/organizations/:id/templates
+	orgEvents.DELETE("/templates/:template_id", h.Event.DeleteTemplate) // DELETE /api/organizations/:id/templates/:template_id
+
+	organizations.GET("/:id/attendance", h.Event.OrganizationAttendance, authMW.RequireOrgPermission(organizationService, domain.PermEditAnyEvent)) // GET /api/organizations/:id/attendance
+	organizations.GET("/:id/feedback", h.Event.OrganizationFeedback, authMW.RequireOrgPermission(organizationService, domain.PermEditAnyEvent))     // GET /api/organizations/:id/feedback
+
+	organizations.PUT("/:id/settings", h.Organization.UpdateSettings, authMW.RequireOrgPermission(organizationService, domain.PermChangeSettings)) // PUT /api/organizations/:id/settings
+
+	orgMembers := organizations.Group("/:id/members", authMW.RequireOrgPermission(organizationService, domain.PermManageMembers))
+	orgMembers.POST("/:user_id/promote", h.Organization.Promote) // POST /api/organizations/:id/members/:user_id/promote
+	orgMembers.POST("/:user_id/demote", h.Organization.Demote)   // POST /api/organizations/:id/members/:user_id/demote
+
+	// -- notifications --
+	notifications := auth.Group("/notifications")
+	notifications.GET("", h.Notification.GetAll)                  // GET /api/notifications
+	notifications.POST("/:event_id/:type", h.Notification.Create) // POST /api/notifications/:event_id/:type
+
+	// -- user --
+	users := auth.Group("/users")
+	users.GET("/:id", h.User.GetByID)         // GET /api/users/:id
+	users.GET("/profile", h.User.GetUserData) // GET /api/users/profile
+}
@@ -0,0 +1,572 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"eventhub-backend/pkg/ticket"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// участники проверки относительно организации мероприятий
+var actorNames = []string{"stranger", "member", "joined", "creator", "host", "moderator", "admin", "owner"}
+
+var (
+	everyone = actorNames
+	viewers  = []string{"member", "joined", "creator", "host", "moderator", "admin", "owner"}
+	editors  = []string{"creator", "host", "moderator", "admin", "owner"}
+	members  = []string{"member", "creator", "host", "moderator", "admin", "owner"}
+)
+
+// access - кому разрешен маршрут для каждого из мероприятий фикстуры
+type access map[string][]string
+
+func sameForAll(allowed []string) access {
+	return access{"public": allowed, "private": allowed, "draft": allowed}
+}
+
+var (
+	// публичный черновик все равно виден только тем, кто его редактирует
+	viewAccess = access{"public": everyone, "private": viewers, "draft": editors}
+	joinAccess = access{"public": everyone, "private": viewers, "draft": nil}
+	editAccess = sameForAll(editors)
+	// комментарий фикстуры написал member: менять его может только автор
+	ownCommentAccess = access{"public": {"member"}, "private": {"member"}, "draft": nil}
+	// удалить комментарий может автор, а чужой - те, кто редактирует мероприятие
+	deleteCommentAccess = access{
+		"public":  append([]string{"member"}, editors...),
+		"private": append([]string{"member"}, editors...),
+		"draft":   editors,
+	}
+	hostsAccess = sameForAll([]string{"creator", "admin", "owner"})
+	// снимается соорганизатор фикстуры, а отказаться от роли он может и сам
+	removeHostAccess = sameForAll([]string{"creator", "host", "admin", "owner"})
+	reviewAccess     = sameForAll([]string{"admin", "owner"})
+	// копия создается в организации, поэтому creator и host с ролью member не могут
+	duplicateAccess = sameForAll([]string{"moderator", "admin", "owner"})
+	creatorsAccess  = sameForAll([]string{"moderator", "admin", "owner"})
+	managersAccess  = sameForAll([]string{"admin", "owner"})
+	settingsAccess  = sameForAll([]string{"owner"})
+	membersAccess   = sameForAll(members)
+	// билет выдается только тем, кто идет на мероприятие
+	ticketAccess = sameForAll([]string{"joined"})
+	// голосуют только участники, а черновик им не виден
+	voteAccess = access{"public": {"joined"}, "private": {"joined"}, "draft": nil}
+	// маршрут работает только с данными самого пользователя
+	ownAccess = sameForAll(everyone)
+)
+
+type routeEvent struct {
+	repository.EventModel
+	commentID    uint
+	pollID       uint
+	optionID     uint
+	ticketTypeID uint
+	attachmentID uint
+	ticket       string
+}
+
+type routeFixture struct {
+	users      map[string]uint
+	orgID      uint
+	inviteCode string
+	templateID uint
+	events     map[string]routeEvent
+}
+
+// upload - тело multipart-запроса с файлом в поле file
+type upload struct {
+	name    string
+	content string
+}
+
+type routeCase struct {
+	route  string // как в комментарии router.go
+	path   func(f routeFixture, e routeEvent) string
+	body   func(f routeFixture, e routeEvent) interface{}
+	access access
+	// check заменяет проверку по коду ответа для маршрутов, которые не
+	// отказывают, а фильтруют
+	check func(t *testing.T, rec *httptest.ResponseRecorder, e routeEvent, allowed bool)
+}
+
+func eventPath(format string) func(routeFixture, routeEvent) string {
+	return func(_ routeFixture, e routeEvent) string {
+		return fmt.Sprintf(format, e.ID)
+	}
+}
+
+func orgPath(format string) func(routeFixture, routeEvent) string {
+	return func(f routeFixture, _ routeEvent) string {
+		return fmt.Sprintf(format, f.orgID)
+	}
+}
+
+func staticPath(path string) func(routeFixture, routeEvent) string {
+	return func(routeFixture, routeEvent) string {
+		return path
+	}
+}
+
+func jsonBody(body interface{}) func(routeFixture, routeEvent) interface{} {
+	return func(routeFixture, routeEvent) interface{} {
+		return body
+	}
+}
+
+func fileBody(name, content string) func(routeFixture, routeEvent) interface{} {
+	return func(routeFixture, routeEvent) interface{} {
+		return upload{name: name, content: content}
+	}
+}
+
+var future = time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+
+// routes - все маршруты /api из router.go. Тела запросов корректны, чтобы
+// разрешенный запрос не отклонялся проверкой данных раньше проверки прав.
+var routes = []routeCase{
+	{route: "POST /api/logout-all", path: staticPath("/api/logout-all"), access: ownAccess},
+
+	{route: "GET /api/events", path: staticPath("/api/events"), access: ownAccess},
+	{route: "GET /api/events/search", path: staticPath("/api/events/search?q=Встреча"), access: ownAccess},
+	{route: "GET /api/events/:id", path: eventPath("/api/events/%d"), access: viewAccess},
+	{route: "GET /api/events/:id/participants", path: eventPath("/api/events/%d/participants"), access: viewAccess},
+	{
+		route: "POST /api/events/:id/join", path: eventPath("/api/events/%d/join"), access: joinAccess,
+		body: func(_ routeFixture, e routeEvent) interface{} {
+			return domain.JoinInput{TicketTypeID: &e.ticketTypeID}
+		},
+	},
+	// выйти можно только из своего участия, чужое не затрагивается
+	{route: "DELETE /api/events/:id/quit", path: eventPath("/api/events/%d/quit"), access: ownAccess},
+	{route: "PUT /api/events/:id/rsvp", path: eventPath("/api/events/%d/rsvp"), access: joinAccess, body: jsonBody(domain.RSVPInput{Status: domain.RSVPMaybe})},
+	{route: "GET /api/events/:id/ticket", path: eventPath("/api/events/%d/ticket?format=json"), access: ticketAccess},
+	{
+		route: "POST /api/events/:id/checkin", path: eventPath("/api/events/%d/checkin"), access: editAccess,
+		body: func(_ routeFixture, e routeEvent) interface{} {
+			return map[string]string{"token": e.ticket}
+		},
+	},
+	{route: "GET /api/events/:id/attendance", path: eventPath("/api/events/%d/attendance"), access: editAccess},
+	{route: "GET /api/events/:id/tickets/revoked", path: eventPath("/api/events/%d/tickets/revoked"), access: editAccess},
+	{route: "GET /api/events/:id/ticket-types", path: eventPath("/api/events/%d/ticket-types"), access: viewAccess},
+	{route: "POST /api/events/:id/ticket-types", path: eventPath("/api/events/%d/ticket-types"), access: editAccess, body: jsonBody(domain.TicketTypeInput{Name: "Стоячие"})},
+	{
+		route: "DELETE /api/events/:id/ticket-types/:type_id", access: editAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/ticket-types/%d", e.ID, e.ticketTypeID)
+		},
+	},
+	{route: "GET /api/events/:id/hosts", path: eventPath("/api/events/%d/hosts"), access: viewAccess},
+	{
+		route: "POST /api/events/:id/hosts", path: eventPath("/api/events/%d/hosts"), access: hostsAccess,
+		body: func(f routeFixture, _ routeEvent) interface{} {
+			return map[string]uint{"user_id": f.users["member"]}
+		},
+	},
+	{
+		route: "DELETE /api/events/:id/hosts/:user_id", access: removeHostAccess,
+		path: func(f routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/hosts/%d", e.ID, f.users["host"])
+		},
+	},
+	{route: "GET /api/events/:id/comments", path: eventPath("/api/events/%d/comments"), access: viewAccess},
+	{route: "POST /api/events/:id/comments", path: eventPath("/api/events/%d/comments"), access: viewAccess, body: jsonBody(domain.CommentInput{Body: "Буду"})},
+	{
+		route: "PATCH /api/events/:id/comments/:comment_id", access: ownCommentAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/comments/%d", e.ID, e.commentID)
+		},
+		body: jsonBody(domain.CommentInput{Body: "Буду позже"}),
+	},
+	{
+		route: "DELETE /api/events/:id/comments/:comment_id", access: deleteCommentAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/comments/%d", e.ID, e.commentID)
+		},
+	},
+	{route: "GET /api/events/:id/polls", path: eventPath("/api/events/%d/polls"), access: viewAccess},
+	{
+		route: "POST /api/events/:id/polls", path: eventPath("/api/events/%d/polls"), access: editAccess,
+		body: jsonBody(domain.PollInput{Question: "Когда?", Options: []domain.PollOptionInput{{Text: "Утром"}, {Text: "Вечером"}}}),
+	},
+	{
+		route: "GET /api/events/:id/polls/:poll_id", access: viewAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/polls/%d", e.ID, e.pollID)
+		},
+	},
+	{
+		route: "PUT /api/events/:id/polls/:poll_id/vote", access: voteAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/polls/%d/vote", e.ID, e.pollID)
+		},
+		body: func(_ routeFixture, e routeEvent) interface{} {
+			return domain.VoteInput{OptionIDs: []uint{e.optionID}}
+		},
+	},
+	{
+		route: "POST /api/events/:id/polls/:poll_id/close", access: editAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/polls/%d/close", e.ID, e.pollID)
+		},
+		body: jsonBody(domain.ClosePollInput{}),
+	},
+	{route: "GET /api/events/:id/feedback", path: eventPath("/api/events/%d/feedback"), access: editAccess},
+	{route: "PUT /api/events/:id/cover", path: eventPath("/api/events/%d/cover"), access: editAccess, body: fileBody("cover.txt", "не картинка")},
+	{route: "DELETE /api/events/:id/cover", path: eventPath("/api/events/%d/cover"), access: editAccess},
+	{route: "POST /api/events/:id/attachments", path: eventPath("/api/events/%d/attachments"), access: editAccess, body: fileBody("plan.txt", "план")},
+	{
+		route: "DELETE /api/events/:id/attachments/:attachment_id", access: editAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return fmt.Sprintf("/api/events/%d/attachments/%d", e.ID, e.attachmentID)
+		},
+	},
+	{route: "GET /api/events/:id/form", path: eventPath("/api/events/%d/form"), access: viewAccess},
+	{
+		route: "PUT /api/events/:id/form", path: eventPath("/api/events/%d/form"), access: editAccess,
+		body: jsonBody(domain.FormInput{Fields: []domain.FormFieldInput{{Label: "Имя", Kind: domain.FormFieldText}}}),
+	},
+	{route: "GET /api/events/:id/form/answers", path: eventPath("/api/events/%d/form/answers"), access: editAccess},
+	{route: "POST /api/events/:id/publish", path: eventPath("/api/events/%d/publish"), access: editAccess, body: jsonBody(domain.PublishInput{})},
+	{route: "POST /api/events/:id/unpublish", path: eventPath("/api/events/%d/unpublish"), access: editAccess},
+	{route: "POST /api/events/:id/approve", path: eventPath("/api/events/%d/approve"), access: reviewAccess},
+	{route: "POST /api/events/:id/reject", path: eventPath("/api/events/%d/reject"), access: reviewAccess, body: jsonBody(domain.StatusReasonInput{Reason: "Нет зала"})},
+	{route: "POST /api/events/:id/cancel", path: eventPath("/api/events/%d/cancel"), access: editAccess, body: jsonBody(domain.StatusReasonInput{Reason: "Нет зала"})},
+	{route: "POST /api/events/:id/postpone", path: eventPath("/api/events/%d/postpone"), access: editAccess, body: jsonBody(domain.StatusReasonInput{Reason: "Нет зала"})},
+	{route: "POST /api/events/:id/resume", path: eventPath("/api/events/%d/resume"), access: editAccess},
+	{route: "GET /api/events/:id/status-history", path: eventPath("/api/events/%d/status-history"), access: editAccess},
+	{route: "GET /api/events/:id/history", path: eventPath("/api/events/%d/history"), access: editAccess},
+	{route: "POST /api/events/:id/duplicate", path: eventPath("/api/events/%d/duplicate"), access: duplicateAccess, body: jsonBody(domain.DuplicateEventInput{StartsAt: future})},
+	{route: "DELETE /api/events/:id/delete", path: eventPath("/api/events/%d/delete"), access: editAccess},
+
+	{route: "GET /api/organizations", path: staticPath("/api/organizations"), access: ownAccess},
+	// мероприятия организации не запрещаются, а отфильтровываются по CanViewEvent
+	{route: "GET /api/organizations/:id/events", path: orgPath("/api/organizations/%d/events"), access: viewAccess, check: checkListed},
+	{route: "GET /api/organizations/:id", path: orgPath("/api/organizations/%d"), access: membersAccess},
+	{route: "GET /api/organizations/:id/members", path: orgPath("/api/organizations/%d/members"), access: membersAccess},
+	{route: "POST /api/organizations/", path: staticPath("/api/organizations"), access: ownAccess, body: jsonBody(map[string]string{"organization_name": "Новая организация"})},
+	{
+		route: "POST /api/organizations/join/:code", access: ownAccess,
+		path: func(f routeFixture, _ routeEvent) string {
+			return "/api/organizations/join/" + f.inviteCode
+		},
+	},
+	// правку проверяет только сервис, без права организации
+	{
+		route: "PUT /api/organizations/:id/events/:event_id/update", access: editAccess,
+		path: func(_ routeFixture, e routeEvent) string {
+			return updatePath(e.EventModel)
+		},
+		body: func(_ routeFixture, e routeEvent) interface{} {
+			return updateInput(e.EventModel)
+		},
+	},
+	{
+		route: "POST /api/organizations/:id/events", path: orgPath("/api/organizations/%d/events"), access: creatorsAccess,
+		body: jsonBody(map[string]interface{}{
+			"title":     "Новая встреча",
+			"starts_at": future.Format(time.RFC3339),
+			"ends_at":   future.Add(time.Hour).Format(time.RFC3339),
+		}),
+	},
+	{route: "GET /api/organizations/:id/templates", path: orgPath("/api/organizations/%d/templates"), access: creatorsAccess},
+	// кроме права организации шаблон проверяет CanDuplicateEvent на мероприятии
+	{
+		route: "POST /api/organizations/:id/templates", path: orgPath("/api/organizations/%d/templates"), access: duplicateAccess,
+		body: func(_ routeFixture, e routeEvent) interface{} {
+			return domain.TemplateInput{Name: "Встреча", EventID: e.ID}
+		},
+	},
+	{
+		route: "DELETE /api/organizations/:id/templates/:template_id", access: creatorsAccess,
+		path: func(f routeFixture, _ routeEvent) string {
+			return fmt.Sprintf("/api/organizations/%d/templates/%d", f.orgID, f.templateID)
+		},
+	},
+	{route: "GET /api/organizations/:id/attendance", path: orgPath("/api/organizations/%d/attendance"), access: creatorsAccess},
+	{route: "GET /api/organizations/:id/feedback", path: orgPath("/api/organizations/%d/feedback"), access: creatorsAccess},
+	{route: "PUT /api/organizations/:id/settings", path: orgPath("/api/organizations/%d/settings"), access: settingsAccess, body: jsonBody(domain.OrganizationSettingsInput{RequireApproval: true})},
+	{
+		route: "POST /api/organizations/:id/members/:user_id/promote", access: managersAccess,
+		path: func(f routeFixture, _ routeEvent) string {
+			return fmt.Sprintf("/api/organizations/%d/members/%d/promote", f.orgID, f.users["member"])
+		},
+	},
+	{
+		route: "POST /api/organizations/:id/members/:user_id/demote", access: managersAccess,
+		path: func(f routeFixture, _ routeEvent) string {
+			return fmt.Sprintf("/api/organizations/%d/members/%d/demote", f.orgID, f.users["moderator"])
+		},
+	},
+
+	{route: "GET /api/notifications", path: staticPath("/api/notifications"), access: ownAccess},
+	// уведомление создается только для самого пользователя
+	{route: "POST /api/notifications/:event_id/:type", path: eventPath("/api/notifications/%d/reminder_1d"), access: ownAccess},
+	// профили участников видны всем авторизованным пользователям
+	{
+		route: "GET /api/users/:id", access: ownAccess,
+		path: func(f routeFixture, _ routeEvent) string {
+			return fmt.Sprintf("/api/users/%d", f.users["member"])
+		},
+	},
+	{route: "GET /api/users/profile", path: staticPath("/api/users/profile"), access: ownAccess},
+}
+
+// uncheckedRoutes - маршруты без таблицы прав и причина
+var uncheckedRoutes = map[string]string{
+	"POST /api/refresh":  "без авторизации, работает с refresh-токеном из запроса",
+	"POST /api/register": "без авторизации",
+	"POST /api/login":    "без авторизации",
+	"POST /api/logout":   "без авторизации, отзывает сессию своего refresh-токена",
+	// отзыв зависит от статуса мероприятия, а не от роли: TestSubmitFeedbackParticipantsOnly
+	"POST /api/events/:id/feedback": "проверяется в TestSubmitFeedbackParticipantsOnly",
+}
+
+// checkListed - мероприятие есть в ответе тогда и только тогда, когда его можно видеть
+func checkListed(t *testing.T, rec *httptest.ResponseRecorder, e routeEvent, allowed bool) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("статус %d, %s", rec.Code, rec.Body)
+	}
+
+	var groups map[string][]struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+		t.Fatal(err)
+	}
+
+	listed := false
+	for _, events := range groups {
+		for _, event := range events {
+			listed = listed || event.ID == e.ID
+		}
+	}
+	if listed != allowed {
+		t.Fatalf("мероприятие в списке: %v, ожидалось %v", listed, allowed)
+	}
+}
+
+func newRouteFixture(app *testApp) routeFixture {
+	app.t.Helper()
+
+	f := routeFixture{users: map[string]uint{}, events: map[string]routeEvent{}}
+	for _, name := range actorNames {
+		f.users[name] = app.user(name)
+	}
+
+	f.orgID = app.organization(f.users["owner"])
+	for name, role := range map[string]string{
+		"member":    domain.RoleMember,
+		"creator":   domain.RoleMember,
+		"host":      domain.RoleMember,
+		"moderator": domain.RoleModerator,
+		"admin":     domain.RoleAdmin,
+	} {
+		app.member(f.orgID, f.users[name], role)
+	}
+
+	var org repository.OrganizationModel
+	if err := app.db.First(&org, f.orgID).Error; err != nil {
+		app.t.Fatal(err)
+	}
+	f.inviteCode = org.InviteCode
+
+	template := repository.EventTemplateModel{OrganizationID: f.orgID, Name: "Шаблон", Title: "Встреча", Timezone: "UTC", Duration: 3600}
+	app.create(&template)
+	f.templateID = template.ID
+
+	for name, event := range map[string]repository.EventModel{
+		"public":  app.event(f.orgID, f.users["creator"], true, domain.EventStatusActive),
+		"private": app.event(f.orgID, f.users["creator"], false, domain.EventStatusActive),
+		"draft":   app.event(f.orgID, f.users["creator"], true, domain.EventStatusDraft),
+	} {
+		e := routeEvent{EventModel: event}
+		app.host(event.ID, f.users["host"])
+		app.participant(event.ID, f.users["joined"], domain.RSVPGoing)
+
+		issued := repository.EventTicketModel{EventID: event.ID, UserID: f.users["joined"]}
+		app.create(&issued)
+		e.ticket = app.tickets.Sign(ticket.Ticket{ID: issued.ID, EventID: event.ID, UserID: f.users["joined"], ExpiresAt: event.EndsAt.Add(2 * time.Hour)})
+
+		comment := repository.EventCommentModel{EventID: event.ID, UserID: f.users["member"], Body: "Приду"}
+		app.create(&comment)
+		e.commentID = comment.ID
+
+		poll := repository.EventPollModel{EventID: event.ID, CreatorID: f.users["creator"], Question: "Где?"}
+		app.create(&poll)
+		option := repository.EventPollOptionModel{PollID: poll.ID, Text: "Зал 2"}
+		app.create(&option)
+		app.create(&repository.EventPollOptionModel{PollID: poll.ID, Text: "Зал 3"})
+		e.pollID, e.optionID = poll.ID, option.ID
+
+		ticketType := repository.EventTicketTypeModel{EventID: event.ID, Name: "Входной"}
+		app.create(&ticketType)
+		e.ticketTypeID = ticketType.ID
+
+		attachment := repository.EventAttachmentModel{
+			EventID:     event.ID,
+			Kind:        repository.AttachmentFile,
+			Name:        "plan.txt",
+			ContentType: "text/plain",
+			Key:         fmt.Sprintf("routes/%d.txt", event.ID),
+			UploadedBy:  f.users["creator"],
+		}
+		app.create(&attachment)
+		e.attachmentID = attachment.ID
+
+		f.events[name] = e
+	}
+
+	return f
+}
+
+// request выполняет маршрут; upload отправляется как multipart
+func (a *testApp) request(userID uint, method, path string, body interface{}) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	file, ok := body.(upload)
+	if !ok {
+		return a.do(userID, method, path, body)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", file.name)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	part.Write([]byte(file.content))
+	writer.Close()
+
+	return a.send(userID, method, path, writer.FormDataContentType(), &buf)
+}
+
+// TestRoutes проходит каждый маршрут через echo, middleware, сервис и
+// репозиторий от имени каждого участника для каждого мероприятия. Каждый
+// запрос откатывается к точке сохранения, поэтому изменения не копятся.
+func TestRoutes(t *testing.T) {
+	app := newTestApp(t)
+	f := newRouteFixture(app)
+	defer func() { app.t = t }()
+
+	for _, r := range routes {
+		method := strings.Fields(r.route)[0]
+
+		for eventName, e := range f.events {
+			allowed := map[string]bool{}
+			for _, name := range r.access[eventName] {
+				allowed[name] = true
+			}
+
+			for _, actorName := range actorNames {
+				t.Run(fmt.Sprintf("%s/%s/%s", r.route, eventName, actorName), func(t *testing.T) {
+					app.t = t
+					if err := app.db.SavePoint("route").Error; err != nil {
+						t.Fatal(err)
+					}
+					defer func() {
+						if err := app.db.RollbackTo("route").Error; err != nil {
+							t.Fatal(err)
+						}
+					}()
+
+					var body interface{}
+					if r.body != nil {
+						body = r.body(f, e)
+					}
+					rec := app.request(f.users[actorName], method, r.path(f, e), body)
+
+					if r.check != nil {
+						r.check(t, rec, e, allowed[actorName])
+						return
+					}
+					switch {
+					case !allowed[actorName] && rec.Code != http.StatusForbidden:
+						t.Fatalf("ожидался отказ 403, статус %d, %s", rec.Code, rec.Body)
+					case allowed[actorName] && (rec.Code == http.StatusForbidden || rec.Code == http.StatusUnauthorized || rec.Code >= 500):
+						t.Fatalf("ожидался доступ, статус %d, %s", rec.Code, rec.Body)
+					}
+				})
+			}
+		}
+	}
+}
+
+// Отзыв оставляют только участники завершенного мероприятия, роль в
+// организации не важна
+func TestSubmitFeedbackParticipantsOnly(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	guest := app.user("guest")
+	admin := app.user("admin")
+	orgID := app.organization(founder)
+	app.member(orgID, admin, domain.RoleAdmin)
+
+	completed := app.event(orgID, founder, false, domain.EventStatusCompleted)
+	app.participant(completed.ID, guest, domain.RSVPGoing)
+	active := app.event(orgID, founder, false, domain.EventStatusActive)
+	app.participant(active.ID, guest, domain.RSVPGoing)
+
+	input := domain.FeedbackInput{Rating: 5}
+	path := func(event repository.EventModel) string {
+		return fmt.Sprintf("/api/events/%d/feedback", event.ID)
+	}
+
+	if rec := app.do(admin, http.MethodPost, path(completed), input); rec.Code != http.StatusForbidden {
+		t.Fatalf("отзыв не участника: статус %d, %s", rec.Code, rec.Body)
+	}
+	if rec := app.do(guest, http.MethodPost, path(active), input); rec.Code == http.StatusForbidden || rec.Code < 400 {
+		t.Fatalf("отзыв о незавершенном мероприятии: статус %d, %s", rec.Code, rec.Body)
+	}
+	if rec := app.do(guest, http.MethodPost, path(completed), input); rec.Code >= 300 {
+		t.Fatalf("отзыв участника: статус %d, %s", rec.Code, rec.Body)
+	}
+}
+
+// TestRoutesCovered не дает добавить маршрут в router.go, не описав его права здесь
+func TestRoutesCovered(t *testing.T) {
+	source, err := os.ReadFile("router.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := map[string]bool{}
+	for _, r := range routes {
+		known[r.route] = true
+	}
+	for route := range uncheckedRoutes {
+		known[route] = true
+	}
+
+	pattern := regexp.MustCompile(`// (GET|POST|PUT|PATCH|DELETE)\s+(/api\S*)`)
+	matches := pattern.FindAllStringSubmatch(string(source), -1)
+	if len(matches) == 0 {
+		t.Fatal("в router.go не найдено ни одного маршрута")
+	}
+
+	registered := map[string]bool{}
+	for _, match := range matches {
+		key := match[1] + " " + strings.TrimSpace(match[2])
+		registered[key] = true
+		if !known[key] {
+			t.Errorf("маршрут %s не описан в таблице прав", key)
+		}
+	}
+	for route := range known {
+		if !registered[route] {
+			t.Errorf("маршрута %s нет в router.go", route)
+		}
+	}
+}
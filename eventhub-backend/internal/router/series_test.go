@@ -0,0 +1,136 @@
+package router
+
+import (
+	"encoding/json"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seriesEvents создает серию по правилу rrule с первым вхождением в startsAt
+// и возвращает ее вхождения по порядку
+func (a *testApp) seriesEvents(orgID, ownerID uint, rrule string, startsAt time.Time) []repository.EventModel {
+	a.t.Helper()
+
+	input := map[string]interface{}{
+		"title":     "Занятие",
+		"location":  "Зал 1",
+		"is_public": true,
+		"starts_at": startsAt.Format(time.RFC3339),
+		"ends_at":   startsAt.Add(time.Hour).Format(time.RFC3339),
+		"timezone":  "UTC",
+		"rrule":     rrule,
+	}
+	if rec := a.do(ownerID, http.MethodPost, fmt.Sprintf("/api/organizations/%d/events", orgID), input); rec.Code != http.StatusNoContent {
+		a.t.Fatalf("создание серии: статус %d, %s", rec.Code, rec.Body)
+	}
+
+	var events []repository.EventModel
+	if err := a.db.Where("organization_id = ? AND series_id IS NOT NULL", orgID).Order("recurrence_date").Find(&events).Error; err != nil {
+		a.t.Fatal(err)
+	}
+	if len(events) < 2 {
+		a.t.Fatalf("в серии %d вхождений", len(events))
+	}
+	return events
+}
+
+// Перенос вхождения на день вперед сдвигает и дни недели в правиле новой
+// серии, иначе следующие разворачивания вернули бы прежние дни
+func TestUpdateFollowingShiftsWeekdays(t *testing.T) {
+	app := newTestApp(t)
+
+	owner := app.user("owner")
+	orgID := app.organization(owner)
+
+	monday := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour).UTC()
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	monday = monday.Add(10 * time.Hour)
+
+	events := app.seriesEvents(orgID, owner, "FREQ=WEEKLY;BYDAY=MO,WE", monday)
+	wednesday := events[1]
+	if wednesday.StartsAt.Weekday() != time.Wednesday {
+		t.Fatalf("второе вхождение в %s", wednesday.StartsAt.Weekday())
+	}
+
+	input := updateInput(wednesday)
+	input["starts_at"] = wednesday.StartsAt.AddDate(0, 0, 1).Format(time.RFC3339)
+	input["ends_at"] = wednesday.EndsAt.AddDate(0, 0, 1).Format(time.RFC3339)
+	if rec := app.do(owner, http.MethodPut, updatePath(wednesday)+"?scope=following", input); rec.Code != http.StatusOK {
+		t.Fatalf("перенос: статус %d, %s", rec.Code, rec.Body)
+	}
+
+	app.reload(&wednesday)
+	var series repository.EventSeriesModel
+	if err := app.db.First(&series, *wednesday.SeriesId).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(series.RRule, "BYDAY=TU,TH") {
+		t.Errorf("правило новой серии %q, ожидались дни TU,TH", series.RRule)
+	}
+	if !series.StartDate.Equal(monday.AddDate(0, 0, 3).Truncate(24 * time.Hour)) {
+		t.Errorf("новая серия начинается %s", series.StartDate)
+	}
+
+	var shifted []repository.EventModel
+	if err := app.db.Where("series_id = ?", series.ID).Find(&shifted).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range shifted {
+		if day := event.StartsAt.Weekday(); day != time.Tuesday && day != time.Thursday {
+			t.Errorf("вхождение %d в %s", event.ID, day)
+		}
+	}
+}
+
+// Правило повторения задается только вместе с scope=following: для одного
+// вхождения или обычного мероприятия оно не должно молча пропадать
+func TestUpdateThisRejectsRRule(t *testing.T) {
+	app := newTestApp(t)
+
+	owner := app.user("owner")
+	orgID := app.organization(owner)
+
+	startsAt := time.Now().AddDate(0, 0, 7).Truncate(time.Hour).UTC()
+	occurrence := app.seriesEvents(orgID, owner, "FREQ=DAILY", startsAt)[0]
+	single := app.event(orgID, owner, true, domain.EventStatusActive)
+
+	for _, tc := range []struct {
+		name  string
+		event repository.EventModel
+		scope string
+	}{
+		{"вхождение", occurrence, "this"},
+		{"обычное мероприятие", single, "following"},
+	} {
+		input := updateInput(tc.event)
+		input["rrule"] = "FREQ=WEEKLY"
+		rec := app.do(owner, http.MethodPut, updatePath(tc.event)+"?scope="+tc.scope, input)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: статус %d, ожидался 400", tc.name, rec.Code)
+			continue
+		}
+
+		var body struct {
+			Fields map[string]string `json:"fields"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Fields["rrule"] == "" {
+			t.Errorf("%s: нет ошибки по полю rrule: %s", tc.name, rec.Body)
+		}
+	}
+
+	app.reload(&occurrence)
+	var series repository.EventSeriesModel
+	if err := app.db.First(&series, *occurrence.SeriesId).Error; err != nil {
+		t.Fatal(err)
+	}
+	if series.RRule != "FREQ=DAILY" {
+		t.Errorf("правило серии изменилось: %q", series.RRule)
+	}
+}
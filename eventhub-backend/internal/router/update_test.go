@@ -0,0 +1,128 @@
+package router
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Соорганизатор с ролью member не имеет права организации на создание
+// мероприятий, но править назначенное мероприятие может
+func TestUpdateByHost(t *testing.T) {
+	app := newTestApp(t)
+
+	founder := app.user("founder")
+	creator := app.user("creator")
+	host := app.user("host")
+	member := app.user("member")
+	formerHost := app.user("former")
+
+	orgID := app.organization(founder)
+	app.member(orgID, creator, domain.RoleModerator)
+	app.member(orgID, host, domain.RoleMember)
+	app.member(orgID, member, domain.RoleMember)
+
+	event := app.event(orgID, creator, true, domain.EventStatusActive)
+	app.host(event.ID, host)
+	// вышел из организации, но запись в event_hosts осталась
+	app.host(event.ID, formerHost)
+
+	input := updateInput(event)
+	input["title"] = "Встреча соорганизатора"
+	if rec := app.do(host, http.MethodPut, updatePath(event), input); rec.Code != http.StatusOK {
+		t.Fatalf("соорганизатор: статус %d, %s", rec.Code, rec.Body)
+	}
+	app.reload(&event)
+	if event.Title != "Встреча соорганизатора" {
+		t.Fatalf("название не изменилось: %q", event.Title)
+	}
+
+	for name, userID := range map[string]uint{"участник организации": member, "бывший соорганизатор": formerHost} {
+		input["title"] = name
+		if rec := app.do(userID, http.MethodPut, updatePath(event), input); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: статус %d, ожидался 403", name, rec.Code)
+		}
+	}
+
+	if rec := app.do(0, http.MethodPut, updatePath(event), input); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без токена: статус %d, ожидался 401", rec.Code)
+	}
+}
+
+// Форма редактирования в приложении не отправляет capacity: число мест без
+// этого поля остается прежним
+func TestUpdateKeepsCapacity(t *testing.T) {
+	app := newTestApp(t)
+
+	owner := app.user("owner")
+	orgID := app.organization(owner)
+
+	event := app.event(orgID, owner, true, domain.EventStatusActive)
+	capacity := 10
+	event.Capacity = &capacity
+	if err := app.db.Save(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	input := updateInput(event)
+	delete(input, "capacity")
+	input["title"] = "Встреча без capacity"
+	if rec := app.do(owner, http.MethodPut, updatePath(event), input); rec.Code != http.StatusOK {
+		t.Fatalf("статус %d, %s", rec.Code, rec.Body)
+	}
+
+	app.reload(&event)
+	if event.Capacity == nil || *event.Capacity != capacity {
+		t.Errorf("число мест %v, ожидалось %d", event.Capacity, capacity)
+	}
+}
+
+// Прошедшее, отмененное и удаленное мероприятие не правится в обход статусов
+func TestUpdateRejectsClosedStatuses(t *testing.T) {
+	app := newTestApp(t)
+
+	owner := app.user("owner")
+	guest := app.user("guest")
+	orgID := app.organization(owner)
+
+	for _, status := range []string{domain.EventStatusDraft, domain.EventStatusActive, domain.EventStatusPostponed} {
+		event := app.event(orgID, owner, true, status)
+		if rec := app.do(owner, http.MethodPut, updatePath(event), updateInput(event)); rec.Code != http.StatusOK {
+			t.Errorf("%s: статус %d, %s", status, rec.Code, rec.Body)
+		}
+	}
+
+	// удаленное мероприятие для правки уже не существует
+	rejected := map[string]int{
+		domain.EventStatusCompleted: http.StatusConflict,
+		domain.EventStatusCancelled: http.StatusConflict,
+		domain.EventStatusDeleted:   http.StatusNotFound,
+	}
+	for status, code := range rejected {
+		event := app.event(orgID, owner, true, status)
+		app.participant(event.ID, guest, domain.RSVPGoing)
+
+		input := updateInput(event)
+		input["starts_at"] = event.StartsAt.Add(24 * time.Hour).Format(time.RFC3339)
+		input["ends_at"] = event.EndsAt.Add(24 * time.Hour).Format(time.RFC3339)
+		if rec := app.do(owner, http.MethodPut, updatePath(event), input); rec.Code != code {
+			t.Errorf("%s: статус %d, ожидался %d", status, rec.Code, code)
+		}
+
+		startsAt := event.StartsAt
+		app.reload(&event)
+		if !event.StartsAt.Equal(startsAt) {
+			t.Errorf("%s: время начала изменилось", status)
+		}
+
+		var notifications int64
+		if err := app.db.Model(&repository.NotificationModel{}).Where("event_id = ? AND user_id = ?", event.ID, guest).Count(&notifications).Error; err != nil {
+			t.Fatal(err)
+		}
+		if notifications != 0 {
+			t.Errorf("%s: участнику отправлено %d уведомлений", status, notifications)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package search
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Общий набор проверок для всех реализаций EventSearcher. Бэкенды берутся из
+// переменных окружения и пропускаются, если не заданы или недоступны:
+//
+//	SEARCH_TEST_POSTGRES_DSN       - все изменения делаются в транзакции и откатываются
+//	SEARCH_TEST_ELASTICSEARCH_URL  - отдельный инстанс: набор перестраивает алиас events
+
+// backend - реализация поиска и способ подготовить для нее данные
+type backend struct {
+	searcher EventSearcher
+	// store сохраняет строки мероприятий, если поиск читает их из базы
+	store func(events []repository.EventModel) error
+	// refresh делает проиндексированное видимым для поиска
+	refresh func() error
+}
+
+const testOrgID = 900001
+
+func TestConformance(t *testing.T) {
+	backends := map[string]func(t *testing.T) backend{
+		"postgres":      postgresBackend,
+		"elasticsearch": elasticBackend,
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+
+			t.Run("IndexAndSearch", func(t *testing.T) { testIndexAndSearch(t, b) })
+			t.Run("Delete", func(t *testing.T) { testDelete(t, b) })
+			t.Run("Bulk", func(t *testing.T) { testBulk(t, b) })
+			t.Run("Reindex", func(t *testing.T) { testReindex(t, b) })
+			t.Run("Access", func(t *testing.T) { testAccess(t, b) })
+			t.Run("DateRangeOverlap", func(t *testing.T) { testDateRange(t, b) })
+			t.Run("Languages", func(t *testing.T) { testLanguages(t, b) })
+			t.Run("DeletedHidden", func(t *testing.T) { testDeletedHidden(t, b) })
+			t.Run("UnpublishedHidden", func(t *testing.T) { testUnpublishedHidden(t, b) })
+		})
+	}
+}
+
+func postgresBackend(t *testing.T) backend {
+	dsn := os.Getenv("SEARCH_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SEARCH_TEST_POSTGRES_DSN не задан")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Skip("Postgres недоступен:", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil || sqlDB.Ping() != nil {
+		t.Skip("Postgres недоступен")
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+		sqlDB.Close()
+	})
+
+	if err := tx.AutoMigrate(&repository.EventModel{}, &repository.EventSearchDocumentModel{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return backend{
+		searcher: NewPostgresSearcher(tx),
+		store: func(events []repository.EventModel) error {
+			for _, event := range events {
+				if err := tx.Save(&event).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		refresh: func() error { return nil },
+	}
+}
+
+func elasticBackend(t *testing.T) backend {
+	url := os.Getenv("SEARCH_TEST_ELASTICSEARCH_URL")
+	if url == "" {
+		t.Skip("SEARCH_TEST_ELASTICSEARCH_URL не задан")
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Skip("Elasticsearch недоступен:", err)
+	}
+	resp.Body.Close()
+
+	searcher := NewElasticSearcher(url)
+	if err := searcher.Reindex(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return backend{
+		searcher: searcher,
+		store:    func([]repository.EventModel) error { return nil },
+		refresh: func() error {
+			resp, err := searcher.do("POST", "/"+eventsAlias+"/_refresh", nil)
+			if err != nil {
+				return err
+			}
+			return resp.Body.Close()
+		},
+	}
+}
+
+var base = time.Date(2030, time.March, 10, 18, 0, 0, 0, time.UTC)
+
+func testEvent(id uint, title string) repository.EventModel {
+	return repository.EventModel{
+		ID:             id,
+		Title:          title,
+		Category:       "music",
+		IsPublic:       true,
+		Status:         domain.EventStatusActive,
+		StartsAt:       base,
+		EndsAt:         base.Add(2 * time.Hour),
+		Timezone:       "Europe/Moscow",
+		CreatorId:      1,
+		OrganizationId: testOrgID,
+	}
+}
+
+// index сохраняет мероприятия и кладет их в индекс по одному
+func (b backend) index(t *testing.T, events ...repository.EventModel) {
+	t.Helper()
+
+	if err := b.store(events); err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range events {
+		if err := b.searcher.Index(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// search ищет только среди мероприятий тестовой организации и возвращает их ID
+func (b backend) search(t *testing.T, params domain.EventSearchParams, access Access) []uint {
+	t.Helper()
+
+	params.OrgID = testOrgID
+	params.Page = 1
+	params.Size = 100
+
+	hits, total, err := b.searcher.Search(params, access)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(hits) {
+		t.Errorf("total = %d, найдено %d", total, len(hits))
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Event.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func expectIDs(t *testing.T, got []uint, want ...uint) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("найдены %v, ожидались %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("найдены %v, ожидались %v", got, want)
+		}
+	}
+}
+
+var anyone = Access{UserID: 42}
+
+func (b backend) reset(t *testing.T) {
+	t.Helper()
+
+	if err := b.searcher.Reindex(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testIndexAndSearch(t *testing.T, b backend) {
+	b.reset(t)
+	b.index(t, testEvent(1, "Джазовый вечер"), testEvent(2, "Шахматный турнир"))
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, anyone), 1, 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "турнир"}, anyone), 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Category: "music"}, anyone), 1, 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Category: "sport"}, anyone))
+
+	// повторная индексация обновляет документ, а не создает второй
+	updated := testEvent(2, "Шахматный блиц")
+	b.index(t, updated)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "турнир"}, anyone))
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "блиц"}, anyone), 2)
+}
+
+func testDelete(t *testing.T, b backend) {
+	b.reset(t)
+	b.index(t, testEvent(1, "Джазовый вечер"), testEvent(2, "Шахматный турнир"))
+
+	if err := b.searcher.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	// удаление отсутствующего документа не ошибка
+	if err := b.searcher.Delete(999); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, anyone), 2)
+}
+
+func testBulk(t *testing.T, b backend) {
+	b.reset(t)
+	b.index(t, testEvent(1, "Джазовый вечер"))
+
+	added := []repository.EventModel{testEvent(2, "Шахматный турнир"), testEvent(3, "Лекция по истории")}
+	if err := b.store(added); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.searcher.Bulk(added, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, anyone), 2, 3)
+}
+
+func testReindex(t *testing.T, b backend) {
+	b.reset(t)
+	b.index(t, testEvent(1, "Джазовый вечер"), testEvent(2, "Шахматный турнир"))
+
+	// полная перестройка оставляет только переданные мероприятия
+	if err := b.searcher.Reindex([]repository.EventModel{testEvent(2, "Шахматный турнир")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, anyone), 2)
+}
+
+func testAccess(t *testing.T, b backend) {
+	b.reset(t)
+
+	private := testEvent(2, "Закрытая встреча")
+	private.IsPublic = false
+	private.CreatorId = 7
+	b.index(t, testEvent(1, "Открытая встреча"), private)
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, Access{UserID: 42}), 1)
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, Access{UserID: 42, OrgIDs: []uint{1}}), 1)
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, Access{UserID: 42, OrgIDs: []uint{testOrgID}}), 1, 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, Access{UserID: 7}), 1, 2)
+}
+
+func testDateRange(t *testing.T, b backend) {
+	b.reset(t)
+
+	// трехдневное мероприятие 10-13 марта и короткое 20 марта
+	long := testEvent(1, "Фестиваль")
+	long.EndsAt = base.Add(72 * time.Hour)
+	short := testEvent(2, "Лекция")
+	short.StartsAt = base.AddDate(0, 0, 10)
+	short.EndsAt = short.StartsAt.Add(time.Hour)
+	b.index(t, long, short)
+
+	at := func(days int) *time.Time {
+		moment := base.AddDate(0, 0, days)
+		return &moment
+	}
+
+	// период внутри мероприятия
+	expectIDs(t, b.search(t, domain.EventSearchParams{From: at(1), To: at(2)}, anyone), 1)
+	// только начало периода
+	expectIDs(t, b.search(t, domain.EventSearchParams{From: at(2)}, anyone), 1, 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{From: at(5)}, anyone), 2)
+	// только конец периода
+	expectIDs(t, b.search(t, domain.EventSearchParams{To: at(5)}, anyone), 1)
+	// полуинтервал: мероприятие, закончившееся ровно в From, не попадает
+	expectIDs(t, b.search(t, domain.EventSearchParams{From: &long.EndsAt, To: at(5)}, anyone))
+	// и начавшееся ровно в To - тоже
+	expectIDs(t, b.search(t, domain.EventSearchParams{From: at(5), To: &short.StartsAt}, anyone))
+}
+
+func testLanguages(t *testing.T, b backend) {
+	b.reset(t)
+
+	concert := testEvent(1, "Концерты классической музыки")
+	concert.Description = "Играет камерный оркестр"
+	workshop := testEvent(2, "Running workshop")
+	workshop.Description = "Morning runners meet in the park"
+	b.index(t, concert, workshop)
+
+	// русская морфология
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "концерт"}, anyone), 1)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "оркестра"}, anyone), 1)
+	// английская морфология
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "runs"}, anyone), 2)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "workshops"}, anyone), 2)
+}
+
+func testDeletedHidden(t *testing.T, b backend) {
+	b.reset(t)
+
+	deleted := testEvent(2, "Удаленная встреча")
+	deleted.Status = domain.EventStatusDeleted
+	b.index(t, testEvent(1, "Встреча"), deleted)
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, anyone), 1)
+}
+
+func testUnpublishedHidden(t *testing.T, b backend) {
+	b.reset(t)
+
+	events := []repository.EventModel{testEvent(1, "Встреча")}
+	for i, status := range domain.UnpublishedStatuses {
+		// публичный черновик не должен быть виден никому, даже создателю
+		event := testEvent(uint(10+i), "Черновик встречи")
+		event.Status = status
+		events = append(events, event)
+	}
+	b.index(t, events...)
+
+	expectIDs(t, b.search(t, domain.EventSearchParams{}, Access{UserID: 1, OrgIDs: []uint{testOrgID}}), 1)
+	expectIDs(t, b.search(t, domain.EventSearchParams{Query: "черновик"}, anyone))
+}
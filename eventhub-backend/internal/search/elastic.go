@@ -0,0 +1,272 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type ElasticSearcher struct {
+	url    string
+	client *http.Client
+}
+
+func NewElasticSearcher(url string) *ElasticSearcher {
+	return &ElasticSearcher{url: url, client: http.DefaultClient}
+}
+
+type esSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source    EventDoc            `json:"_source"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (s *ElasticSearcher) Index(event repository.EventModel) error {
+	body, err := json.Marshal(NewEventDoc(event))
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do("PUT", fmt.Sprintf("/%s/_doc/%d", eventsAlias, event.ID), body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *ElasticSearcher) Delete(eventID uint) error {
+	resp, err := s.do("DELETE", fmt.Sprintf("/%s/_doc/%d", eventsAlias, eventID), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+const bulkBatchSize = 500
+
+// Reindex строит новую версию индекса и переключает на нее алиас events,
+// поиск во время перестроения продолжает работать по старой версии
+func (s *ElasticSearcher) Reindex(events []repository.EventModel) error {
+	return s.rebuild(func(index string) error {
+		for start := 0; start < len(events); start += bulkBatchSize {
+			end := min(start+bulkBatchSize, len(events))
+			if err := s.bulk(index, events[start:end], nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+func (s *ElasticSearcher) Bulk(index []repository.EventModel, deleteIDs []uint) error {
+	return s.bulk(eventsAlias, index, deleteIDs)
+}
+
+func (s *ElasticSearcher) bulk(target string, index []repository.EventModel, deleteIDs []uint) error {
+	if len(index) == 0 && len(deleteIDs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range index {
+		encoder.Encode(map[string]interface{}{"index": map[string]interface{}{"_index": target, "_id": fmt.Sprint(event.ID)}})
+		encoder.Encode(NewEventDoc(event))
+	}
+	for _, eventID := range deleteIDs {
+		encoder.Encode(map[string]interface{}{"delete": map[string]interface{}{"_index": target, "_id": fmt.Sprint(eventID)}})
+	}
+
+	req, err := http.NewRequest("POST", s.url+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("elasticsearch returned error: %s", respBody)
+	}
+
+	var result esBulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	for _, item := range result.Items {
+		for op, status := range item {
+			// документа уже нет в индексе - удалять нечего
+			if op == "delete" && status.Status == http.StatusNotFound {
+				continue
+			}
+			if status.Status >= 300 {
+				return fmt.Errorf("elasticsearch bulk %s %s failed: %s", op, status.ID, status.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *ElasticSearcher) Search(params domain.EventSearchParams, access Access) ([]Hit, int, error) {
+	body, err := json.Marshal(buildElasticQuery(params, access))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := s.do("POST", "/"+eventsAlias+"/_search", body)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var result esSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]Hit, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		hits = append(hits, Hit{Event: hit.Source, Highlight: hit.Highlight})
+	}
+
+	return hits, result.Hits.Total.Value, nil
+}
+
+// do выполняет запрос к ES; 404 на удалении считается успехом
+func (s *ElasticSearcher) do(method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 && !(method == "DELETE" && resp.StatusCode == http.StatusNotFound) {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("elasticsearch returned error: %s", respBody)
+	}
+
+	return resp, nil
+}
+
+func buildElasticQuery(params domain.EventSearchParams, access Access) map[string]interface{} {
+	var must []interface{}
+	if params.Query == "" {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	} else {
+		must = append(must, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     params.Query,
+				"type":      "best_fields",
+				"fields":    []string{"title^3", "category^2", "description", "location"},
+				"fuzziness": "AUTO",
+				"operator":  "and",
+			},
+		})
+	}
+
+	accessFilter := []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"is_public": true}},
+		map[string]interface{}{"term": map[string]interface{}{"creator_id": access.UserID}},
+	}
+	if len(access.OrgIDs) > 0 {
+		accessFilter = append(accessFilter, map[string]interface{}{"terms": map[string]interface{}{"organization_id": access.OrgIDs}})
+	}
+
+	filter := []interface{}{
+		map[string]interface{}{"bool": map[string]interface{}{"should": accessFilter, "minimum_should_match": 1}},
+		// неопубликованные мероприятия не индексируются, но фильтр страхует от
+		// документов, оставшихся после снятия с публикации
+		map[string]interface{}{"terms": map[string]interface{}{"status": domain.StatusGroups}},
+	}
+	if params.Category != "" {
+		filter = append(filter, map[string]interface{}{"term": map[string]interface{}{"category.keyword": params.Category}})
+	}
+	if params.OrgID != 0 {
+		filter = append(filter, map[string]interface{}{"term": map[string]interface{}{"organization_id": params.OrgID}})
+	}
+	// многодневное мероприятие попадает в период, если пересекается с ним
+	if params.From != nil {
+		filter = append(filter, map[string]interface{}{"range": map[string]interface{}{"ends_at": map[string]interface{}{"gt": params.From.UTC().Format(time.RFC3339)}}})
+	}
+	if params.To != nil {
+		filter = append(filter, map[string]interface{}{"range": map[string]interface{}{"starts_at": map[string]interface{}{"lt": params.To.UTC().Format(time.RFC3339)}}})
+	}
+
+	var query interface{} = map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   must,
+			"filter": filter,
+		},
+	}
+	// при поиске по тексту хорошо оцененные мероприятия немного поднимаются;
+	// ln2p не обнуляет релевантность мероприятий без оценок
+	if params.Query != "" {
+		query = map[string]interface{}{
+			"function_score": map[string]interface{}{
+				"query": query,
+				"field_value_factor": map[string]interface{}{
+					"field":    "rating_average",
+					"modifier": "ln2p",
+					"missing":  0,
+				},
+				"boost_mode": "multiply",
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"from":  (params.Page - 1) * params.Size,
+		"size":  params.Size,
+		"query": query,
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"title":       map[string]interface{}{"number_of_fragments": 0},
+				"description": map[string]interface{}{"fragment_size": 150, "number_of_fragments": 3},
+				"location":    map[string]interface{}{"number_of_fragments": 0},
+			},
+		},
+	}
+}
@@ -0,0 +1,354 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	eventsAlias       = "events"
+	eventsIndexPrefix = "events_v"
+	// templateVersion нужно увеличивать при любом изменении настроек или маппинга:
+	// EnsureIndex сравнивает ее с версией в _meta маппинга и пересобирает индекс
+	templateVersion = 6
+)
+
+var eventsTemplate = map[string]interface{}{
+	"index_patterns": []string{eventsIndexPrefix + "*"},
+	"version":        templateVersion,
+	"template": map[string]interface{}{
+		"settings": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"russian_stop":    map[string]interface{}{"type": "stop", "stopwords": "_russian_"},
+					"russian_stemmer": map[string]interface{}{"type": "stemmer", "language": "russian"},
+					"english_stop":    map[string]interface{}{"type": "stop", "stopwords": "_english_"},
+					"english_stemmer": map[string]interface{}{"type": "stemmer", "language": "english"},
+					"yo_to_ye":        map[string]interface{}{"type": "pattern_replace", "pattern": "ё", "replacement": "е"},
+				},
+				"analyzer": map[string]interface{}{
+					// стеммеры не мешают друг другу: русский не трогает латиницу, английский - кириллицу
+					"ru_en": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "yo_to_ye", "russian_stop", "english_stop", "russian_stemmer", "english_stemmer"},
+					},
+				},
+				"normalizer": map[string]interface{}{
+					"lowercase": map[string]interface{}{"type": "custom", "filter": []string{"lowercase"}},
+				},
+			},
+		},
+		"mappings": map[string]interface{}{
+			"_meta":   map[string]interface{}{"template_version": templateVersion},
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"id":              map[string]interface{}{"type": "long"},
+				"title":           textWithKeyword(),
+				"description":     map[string]interface{}{"type": "text", "analyzer": "ru_en"},
+				"category":        textWithKeyword(),
+				"status":          map[string]interface{}{"type": "keyword"},
+				"location":        textWithKeyword(),
+				"is_public":       map[string]interface{}{"type": "boolean"},
+				"starts_at":       map[string]interface{}{"type": "date"},
+				"ends_at":         map[string]interface{}{"type": "date"},
+				"timezone":        map[string]interface{}{"type": "keyword"},
+				"date":            map[string]interface{}{"type": "date", "format": "yyyy-MM-dd"},
+				"end_date":        map[string]interface{}{"type": "date", "format": "yyyy-MM-dd"},
+				"start_time":      map[string]interface{}{"type": "date", "format": "HH:mm"},
+				"end_time":        map[string]interface{}{"type": "date", "format": "HH:mm"},
+				"organization_id": map[string]interface{}{"type": "long"},
+				"creator_id":      map[string]interface{}{"type": "long"},
+				"rating_average":  map[string]interface{}{"type": "float"},
+				"rating_count":    map[string]interface{}{"type": "integer"},
+				"cover_url":       map[string]interface{}{"type": "keyword", "index": false},
+				"cover_thumb_url": map[string]interface{}{"type": "keyword", "index": false},
+			},
+		},
+	},
+}
+
+func textWithKeyword() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "text",
+		"analyzer": "ru_en",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256, "normalizer": "lowercase"},
+		},
+	}
+}
+
+// EnsureIndex устанавливает шаблон индекса и, если алиаса еще нет, создает
+// первую версию индекса под ним. Индекс, собранный по старому шаблону, и индекс
+// "events" до появления алиасов переносятся в новую версию: с dynamic: false
+// новые поля старого маппинга не индексируются, и фильтры по ним ничего не находят.
+func (s *ElasticSearcher) EnsureIndex() error {
+	if err := s.putTemplate(); err != nil {
+		return err
+	}
+
+	current, err := s.aliasIndices()
+	if err != nil {
+		return err
+	}
+	if len(current) > 0 {
+		outdated, err := s.outdated(current)
+		if err != nil || !outdated {
+			return err
+		}
+		return s.migrate()
+	}
+
+	legacy, err := s.exists("/" + eventsAlias)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		return s.migrate()
+	}
+
+	name := eventsIndexPrefix + "1"
+	if err := s.createIndex(name); err != nil {
+		return err
+	}
+
+	return s.swapAlias(name, nil, false)
+}
+
+type esMappingsResponse map[string]struct {
+	Mappings struct {
+		Meta struct {
+			TemplateVersion int `json:"template_version"`
+		} `json:"_meta"`
+	} `json:"mappings"`
+}
+
+// outdated проверяет, что хотя бы один индекс собран не по текущему шаблону
+func (s *ElasticSearcher) outdated(indices []string) (bool, error) {
+	resp, err := s.do("GET", "/"+strings.Join(indices, ",")+"/_mapping", nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var result esMappingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+
+	for _, index := range result {
+		if index.Mappings.Meta.TemplateVersion != templateVersion {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// migrate копирует документы в новую версию индекса через _reindex на стороне
+// ES, без обращения к базе. Поиск до переключения алиаса работает по старой версии.
+func (s *ElasticSearcher) migrate() error {
+	log.Println("Индекс Elasticsearch собран по старому шаблону, перенос в версию", templateVersion)
+
+	return s.rebuild(func(index string) error {
+		body, err := json.Marshal(map[string]interface{}{
+			"source": map[string]interface{}{"index": eventsAlias},
+			"dest":   map[string]interface{}{"index": index},
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.do("POST", "/_reindex?wait_for_completion=true", body)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		var result struct {
+			Failures []json.RawMessage `json:"failures"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return err
+		}
+		if len(result.Failures) > 0 {
+			return fmt.Errorf("elasticsearch reindex failed: %s", result.Failures[0])
+		}
+
+		return nil
+	})
+}
+
+// rebuild собирает новую версию индекса, заполняет ее через fill и
+// атомарно переключает на нее алиас, после чего удаляет старые версии
+func (s *ElasticSearcher) rebuild(fill func(index string) error) error {
+	if err := s.putTemplate(); err != nil {
+		return err
+	}
+
+	current, err := s.aliasIndices()
+	if err != nil {
+		return err
+	}
+
+	legacy := false
+	if len(current) == 0 {
+		if legacy, err = s.exists("/" + eventsAlias); err != nil {
+			return err
+		}
+	}
+
+	name := eventsIndexPrefix + strconv.Itoa(nextIndexVersion(current))
+	if err := s.createIndex(name); err != nil {
+		return err
+	}
+
+	if err := fill(name); err != nil {
+		if resp, delErr := s.do("DELETE", "/"+name, nil); delErr == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	resp, err := s.do("POST", "/"+name+"/_refresh", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if err := s.swapAlias(name, current, legacy); err != nil {
+		return err
+	}
+
+	for _, old := range current {
+		if resp, err := s.do("DELETE", "/"+old, nil); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	return nil
+}
+
+func (s *ElasticSearcher) putTemplate() error {
+	body, err := json.Marshal(eventsTemplate)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do("PUT", "/_index_template/"+eventsAlias, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *ElasticSearcher) createIndex(name string) error {
+	resp, err := s.do("PUT", "/"+name, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// swapAlias за одно действие переводит алиас на новый индекс. Индекс "events",
+// созданный до появления алиасов, удаляется в том же запросе.
+func (s *ElasticSearcher) swapAlias(name string, old []string, removeLegacy bool) error {
+	var actions []interface{}
+	if removeLegacy {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": eventsAlias}})
+	}
+	for _, index := range old {
+		actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": index, "alias": eventsAlias}})
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]interface{}{"index": name, "alias": eventsAlias, "is_write_index": true}})
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do("POST", "/_aliases", body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *ElasticSearcher) aliasIndices() ([]string, error) {
+	req, err := http.NewRequest("GET", s.url+"/_alias/"+eventsAlias, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("elasticsearch returned status %d for alias lookup", resp.StatusCode)
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	return indices, nil
+}
+
+func (s *ElasticSearcher) exists(path string) (bool, error) {
+	req, err := http.NewRequest("HEAD", s.url+path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, errors.New("unexpected elasticsearch status " + resp.Status)
+}
+
+func nextIndexVersion(indices []string) int {
+	next := 1
+	for _, index := range indices {
+		version, err := strconv.Atoi(strings.TrimPrefix(index, eventsIndexPrefix))
+		if err == nil && version >= next {
+			next = version + 1
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"encoding/json"
+	"eventhub-backend/internal/domain"
+	"testing"
+	"time"
+)
+
+// Индекс, собранный по старому маппингу с dynamic: false, не знает новых полей:
+// фильтр по датам ничего не находит, пока EnsureIndex не перенесет документы
+func TestEnsureIndexMigratesOutdated(t *testing.T) {
+	b := elasticBackend(t)
+	searcher := b.searcher.(*ElasticSearcher)
+	b.reset(t)
+
+	current, err := searcher.aliasIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// имя вне шаблона events_v*, чтобы маппинг остался старым
+	stale := "events_stale_test"
+	mapping, err := json.Marshal(map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"id":              map[string]interface{}{"type": "long"},
+				"title":           map[string]interface{}{"type": "text"},
+				"status":          map[string]interface{}{"type": "keyword"},
+				"is_public":       map[string]interface{}{"type": "boolean"},
+				"organization_id": map[string]interface{}{"type": "long"},
+				"creator_id":      map[string]interface{}{"type": "long"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := searcher.do("PUT", "/"+stale, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := searcher.swapAlias(stale, current, false); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { searcher.Reindex(nil) })
+
+	b.index(t, testEvent(1, "Фестиваль"))
+	from := base.Add(-time.Hour)
+	period := domain.EventSearchParams{From: &from}
+	expectIDs(t, b.search(t, period, anyone))
+
+	if err := searcher.EnsureIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	indices, err := searcher.aliasIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 1 || indices[0] == stale {
+		t.Fatalf("алиас указывает на %v, ожидалась новая версия индекса", indices)
+	}
+	outdated, err := searcher.outdated(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outdated {
+		t.Fatal("после переноса индекс все еще собран по старому шаблону")
+	}
+	expectIDs(t, b.search(t, period, anyone), 1)
+
+	// повторный запуск с актуальным индексом ничего не пересобирает
+	if err := searcher.EnsureIndex(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := searcher.aliasIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 1 || again[0] != indices[0] {
+		t.Fatalf("актуальный индекс пересобран: %v -> %v", indices, again)
+	}
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSearcher ищет по tsvector-документам в event_search_documents.
+// Документ строится сразу в русской и английской конфигурациях.
+type PostgresSearcher struct {
+	db *gorm.DB
+}
+
+func NewPostgresSearcher(db *gorm.DB) *PostgresSearcher {
+	return &PostgresSearcher{db: db}
+}
+
+const documentSQL = `
+	setweight(to_tsvector('russian', coalesce(@title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(@title, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(@category, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(@category, '')), 'B') ||
+	setweight(to_tsvector('russian', coalesce(@description, '')), 'C') ||
+	setweight(to_tsvector('english', coalesce(@description, '')), 'C') ||
+	setweight(to_tsvector('russian', coalesce(@location, '')), 'D')`
+
+const querySQL = `(websearch_to_tsquery('russian', @query) || websearch_to_tsquery('english', @query))`
+
+const headlineOptions = `'StartSel=<em>, StopSel=</em>, HighlightAll=true'`
+
+type pgSearchRow struct {
+	repository.EventModel
+	TitleHighlight       string
+	DescriptionHighlight string
+	LocationHighlight    string
+	Total                int
+}
+
+func (s *PostgresSearcher) Index(event repository.EventModel) error {
+	return indexDocument(s.db, event)
+}
+
+func (s *PostgresSearcher) Delete(eventID uint) error {
+	return s.db.Where("event_id = ?", eventID).Delete(&repository.EventSearchDocumentModel{}).Error
+}
+
+func (s *PostgresSearcher) Reindex(events []repository.EventModel) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM event_search_documents").Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := indexDocument(tx, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *PostgresSearcher) Bulk(index []repository.EventModel, deleteIDs []uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range index {
+			if err := indexDocument(tx, event); err != nil {
+				return err
+			}
+		}
+
+		if len(deleteIDs) > 0 {
+			if err := tx.Where("event_id IN ?", deleteIDs).Delete(&repository.EventSearchDocumentModel{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *PostgresSearcher) Search(params domain.EventSearchParams, access Access) ([]Hit, int, error) {
+	args := map[string]interface{}{
+		"query":   params.Query,
+		"user_id": access.UserID,
+		"limit":   params.Size,
+		"offset":  (params.Page - 1) * params.Size,
+		// черновики, ожидающие одобрения и удаленные мероприятия в поиск не попадают
+		"published": domain.StatusGroups,
+	}
+
+	where := []string{"e.status IN @published"}
+	accessSQL := "e.is_public OR e.creator_id = @user_id"
+	if len(access.OrgIDs) > 0 {
+		accessSQL += " OR e.organization_id IN @org_ids"
+		args["org_ids"] = access.OrgIDs
+	}
+	where = append(where, "("+accessSQL+")")
+
+	if params.Query != "" {
+		where = append(where, "d.document @@ "+querySQL)
+	}
+	if params.Category != "" {
+		where = append(where, "e.category = @category")
+		args["category"] = params.Category
+	}
+	if params.OrgID != 0 {
+		where = append(where, "e.organization_id = @org_id")
+		args["org_id"] = params.OrgID
+	}
+	// многодневное мероприятие попадает в период, если пересекается с ним
+	if params.From != nil {
+		where = append(where, "e.ends_at > @from")
+		args["from"] = params.From.UTC()
+	}
+	if params.To != nil {
+		where = append(where, "e.starts_at < @to")
+		args["to"] = params.To.UTC()
+	}
+
+	order := "e.starts_at"
+	headline := func(column string) string { return "''" }
+	if params.Query != "" {
+		// средняя оценка немного поднимает мероприятие, как и в Elasticsearch
+		order = "ts_rank(d.document, " + querySQL + ") * ln(2 + coalesce(e.rating_average, 0)) DESC, e.starts_at"
+		headline = func(column string) string {
+			return "ts_headline('russian', coalesce(e." + column + ", ''), " + querySQL + ", " + headlineOptions + ")"
+		}
+	}
+
+	sql := `SELECT e.*,
+		` + headline("title") + ` AS title_highlight,
+		` + headline("description") + ` AS description_highlight,
+		` + headline("location") + ` AS location_highlight,
+		count(*) OVER () AS total
+		FROM events e
+		JOIN event_search_documents d ON d.event_id = e.id
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + order + `
+		LIMIT @limit OFFSET @offset`
+
+	var rows []pgSearchRow
+	if err := s.db.Raw(sql, args).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]Hit, 0, len(rows))
+	total := 0
+	for _, row := range rows {
+		total = row.Total
+		hits = append(hits, Hit{
+			Event:     NewEventDoc(row.EventModel),
+			Highlight: highlights(row),
+		})
+	}
+
+	return hits, total, nil
+}
+
+func indexDocument(db *gorm.DB, event repository.EventModel) error {
+	return db.Exec(`INSERT INTO event_search_documents (event_id, document)
+		VALUES (@id, `+documentSQL+`)
+		ON CONFLICT (event_id) DO UPDATE SET document = EXCLUDED.document`,
+		map[string]interface{}{
+			"id":          event.ID,
+			"title":       event.Title,
+			"category":    event.Category,
+			"description": event.Description,
+			"location":    event.Location,
+		}).Error
+}
+
+// highlights оставляет только поля, в которых нашлось совпадение
+func highlights(row pgSearchRow) map[string][]string {
+	result := make(map[string][]string)
+	for field, value := range map[string]string{
+		"title":       row.TitleHighlight,
+		"description": row.DescriptionHighlight,
+		"location":    row.LocationHighlight,
+	} {
+		if strings.Contains(value, "<em>") {
+			result[field] = []string{value}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EventSearcher interface {
+	Index(event repository.EventModel) error
+	Delete(eventID uint) error
+	Search(params domain.EventSearchParams, access Access) ([]Hit, int, error)
+	Reindex(events []repository.EventModel) error
+	// Bulk применяет пачку изменений за один запрос
+	Bulk(index []repository.EventModel, deleteIDs []uint) error
+}
+
+// New выбирает реализацию поиска по имени бэкенда из конфигурации
+func New(backend, elasticURL string, db *gorm.DB) (EventSearcher, error) {
+	switch backend {
+	case "elasticsearch":
+		searcher := NewElasticSearcher(elasticURL)
+		if err := searcher.EnsureIndex(); err != nil {
+			log.Println("Ошибка при подготовке индекса Elasticsearch:", err)
+		}
+		return searcher, nil
+	case "postgres":
+		return NewPostgresSearcher(db), nil
+	}
+
+	return nil, fmt.Errorf("unknown search backend %q", backend)
+}
+
+// Access ограничивает выдачу: закрытые мероприятия видны только участникам
+// организаций и их создателям
+type Access struct {
+	UserID uint
+	OrgIDs []uint
+}
+
+type EventDoc struct {
+	ID             uint      `json:"id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	Status         string    `json:"status"`
+	Location       string    `json:"location"`
+	IsPublic       bool      `json:"is_public"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Timezone       string    `json:"timezone"`
+	Date           string    `json:"date"`
+	EndDate        string    `json:"end_date"`
+	StartTime      string    `json:"start_time"`
+	EndTime        string    `json:"end_time"`
+	OrganizationID uint      `json:"organization_id"`
+	CreatorID      uint      `json:"creator_id"`
+	RatingAverage  *float64  `json:"rating_average"`
+	RatingCount    int       `json:"rating_count"`
+	CoverURL       string    `json:"cover_url"`
+	CoverThumbURL  string    `json:"cover_thumb_url"`
+}
+
+type Hit struct {
+	Event     EventDoc            `json:"event"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+// NewEventDoc хранит время в часовом поясе мероприятия; date, end_date,
+// start_time и end_time - местные даты и часы для отображения
+func NewEventDoc(event repository.EventModel) EventDoc {
+	loc := event.TimeLocation()
+	startsAt := event.StartsAt.In(loc)
+	endsAt := event.EndsAt.In(loc)
+
+	return EventDoc{
+		ID:             event.ID,
+		Title:          event.Title,
+		Description:    event.Description,
+		Category:       event.Category,
+		Status:         event.Status,
+		Location:       event.Location,
+		IsPublic:       event.IsPublic,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		Timezone:       loc.String(),
+		Date:           startsAt.Format("2006-01-02"),
+		EndDate:        endsAt.Format("2006-01-02"),
+		StartTime:      startsAt.Format("15:04"),
+		EndTime:        endsAt.Format("15:04"),
+		OrganizationID: event.OrganizationId,
+		CreatorID:      event.CreatorId,
+		RatingAverage:  event.RatingAverage,
+		RatingCount:    event.RatingCount,
+		CoverURL:       event.CoverURL,
+		CoverThumbURL:  event.CoverThumbURL,
+	}
+}
@@ -7,33 +7,55 @@ import (
 	"eventhub-backend/internal/repository"
 	customHash "eventhub-backend/pkg/hash"
 	customJwt "eventhub-backend/pkg/jwt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type AuthService struct {
-	cfg        config.Config
-	userRepo   repository.GormUserRepository
-	jwtManager customJwt.Manager
+	cfg         config.Config
+	userRepo    repository.GormUserRepository
+	sessionRepo repository.GormSessionRepository
+	jwtManager  customJwt.Manager
 }
 
-func NewAuthService(cfg config.Config, userRepo repository.GormUserRepository, jwtManager customJwt.Manager) *AuthService {
-	return &AuthService{cfg: cfg, userRepo: userRepo, jwtManager: jwtManager}
+func NewAuthService(cfg config.Config, userRepo repository.GormUserRepository, sessionRepo repository.GormSessionRepository, jwtManager customJwt.Manager) *AuthService {
+	return &AuthService{cfg: cfg, userRepo: userRepo, sessionRepo: sessionRepo, jwtManager: jwtManager}
 }
 
-func (s *AuthService) RefreshTokens(refreshToken string) (string, string, error) {
-	userID, _ := s.jwtManager.ParseToken(refreshToken)
-	access, err := s.jwtManager.GenerateAccessToken(userID)
+func (s *AuthService) RefreshTokens(refreshToken string, device domain.DeviceInfo) (string, string, error) {
+	userID, jti, err := s.jwtManager.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return "", "", err
+		return "", "", errors.New("invalid refresh token")
 	}
-	refresh, err := s.jwtManager.GenerateRefreshToken(userID)
+
+	session, err := s.sessionRepo.GetByJTI(jti)
+	if err != nil {
+		return "", "", errors.New("invalid refresh token")
+	}
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return "", "", errors.New("invalid refresh token")
+	}
+
+	next := s.newSession(userID, session.FamilyID, device)
+	rotated, err := s.sessionRepo.Rotate(jti, next)
 	if err != nil {
 		return "", "", err
 	}
 
-	return access, refresh, nil
+	// токен уже был обменян ранее - считаем, что он украден, и отзываем всю цепочку
+	if !rotated {
+		if err := s.sessionRepo.RevokeFamily(session.FamilyID); err != nil {
+			log.Printf("Ошибка при отзыве сессий семьи %s: %v", session.FamilyID, err)
+		}
+		return "", "", errors.New("refresh token reuse detected")
+	}
+
+	return s.issueTokens(userID, next.JTI)
 }
 
-func (s *AuthService) Login(input domain.LoginInput) (string, string, error) {
+func (s *AuthService) Login(input domain.LoginInput, device domain.DeviceInfo) (string, string, error) {
 	user, err := s.userRepo.FindByUsername(input.Username)
 	if err != nil {
 		return "", "", errors.New("invalid credentials")
@@ -43,11 +65,49 @@ func (s *AuthService) Login(input domain.LoginInput) (string, string, error) {
 		return "", "", errors.New("invalid credentials")
 	}
 
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID)
+	session := s.newSession(user.ID, uuid.NewString(), device)
+	if err := s.sessionRepo.Create(session); err != nil {
+		return "", "", err
+	}
+
+	return s.issueTokens(user.ID, session.JTI)
+}
+
+func (s *AuthService) Logout(refreshToken string) error {
+	_, jti, err := s.jwtManager.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetByJTI(jti)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	return s.sessionRepo.RevokeFamily(session.FamilyID)
+}
+
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.sessionRepo.RevokeAllForUser(userID)
+}
+
+func (s *AuthService) newSession(userID uint, familyID string, device domain.DeviceInfo) *repository.SessionModel {
+	return &repository.SessionModel{
+		JTI:       uuid.NewString(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		UserAgent: device.UserAgent,
+		IP:        device.IP,
+		ExpiresAt: time.Now().Add(customJwt.RefreshTokenTTL),
+	}
+}
+
+func (s *AuthService) issueTokens(userID uint, jti string) (string, string, error) {
+	accessToken, err := s.jwtManager.GenerateAccessToken(userID)
 	if err != nil {
 		return "", "", err
 	}
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(userID, jti)
 	if err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"eventhub-backend/pkg/ticket"
+	"time"
+)
+
+// билет остается действительным еще какое-то время после окончания,
+// чтобы опоздавших можно было отметить
+const ticketGracePeriod = 2 * time.Hour
+
+// ticketExpiresAt считается от текущего расписания: после переноса
+// мероприятия ранее выданные билеты продолжают действовать
+func ticketExpiresAt(event repository.EventModel) time.Time {
+	return event.EndsAt.Add(ticketGracePeriod)
+}
+
+func ticketCancelled(event repository.EventModel) bool {
+	return event.Status == domain.EventStatusCancelled || event.Status == domain.EventStatusDeleted
+}
+
+type IssuedTicket struct {
+	Token        string    `json:"ticket"`
+	TicketID     uint      `json:"ticket_id"`
+	TicketTypeID *uint     `json:"ticket_type_id"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Ticket выдает подписанный билет участнику, который идет на мероприятие.
+// Билет проверяется сканером без сети по публичному ключу из TicketKeys.
+func (s *EventService) Ticket(userID, eventID uint) (IssuedTicket, error) {
+	if s.ticketSigner == nil {
+		return IssuedTicket{}, errors.New("tickets disabled")
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil || event.Status == domain.EventStatusDeleted {
+		return IssuedTicket{}, errors.New("event not found")
+	}
+	if event.Status == domain.EventStatusCancelled {
+		return IssuedTicket{}, errors.New("event cancelled")
+	}
+
+	expiresAt := ticketExpiresAt(event)
+	if expiresAt.Before(time.Now()) {
+		return IssuedTicket{}, errors.New("event finished")
+	}
+
+	issued, err := s.eventRepo.GetTicket(userID, eventID)
+	if err != nil {
+		return IssuedTicket{}, err
+	}
+
+	payload := ticket.Ticket{ID: issued.ID, EventID: eventID, UserID: userID, ExpiresAt: expiresAt}
+	if issued.TicketTypeID != nil {
+		payload.TypeID = *issued.TicketTypeID
+	}
+
+	return IssuedTicket{
+		Token:        s.ticketSigner.Sign(payload),
+		TicketID:     issued.ID,
+		TicketTypeID: issued.TicketTypeID,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// CheckIn отмечает приход по отсканированному билету. Отмечать могут те,
+// кто может редактировать мероприятие.
+func (s *EventService) CheckIn(organizerID, eventID uint, token string) (repository.EventParticipantModel, bool, error) {
+	if err := s.authorize(organizerID, eventID, policy.CanEditEvent); err != nil {
+		return repository.EventParticipantModel{}, false, err
+	}
+
+	if s.ticketSigner == nil {
+		return repository.EventParticipantModel{}, false, errors.New("tickets disabled")
+	}
+
+	// срок, подписанный в билете, мог устареть после переноса - сверяемся с мероприятием
+	payload, err := s.ticketSigner.VerifySignature(token)
+	if err != nil {
+		return repository.EventParticipantModel{}, false, errors.New("invalid ticket")
+	}
+	if payload.EventID != eventID {
+		return repository.EventParticipantModel{}, false, errors.New("ticket for another event")
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return repository.EventParticipantModel{}, false, err
+	}
+	if ticketCancelled(event) {
+		return repository.EventParticipantModel{}, false, errors.New("event cancelled")
+	}
+	if time.Now().After(ticketExpiresAt(event)) {
+		return repository.EventParticipantModel{}, false, errors.New("ticket expired")
+	}
+
+	issued, err := s.eventRepo.GetTicketByID(payload.ID)
+	if err != nil || issued.UserID != payload.UserID || issued.EventID != eventID {
+		return repository.EventParticipantModel{}, false, errors.New("invalid ticket")
+	}
+	if issued.RevokedAt != nil {
+		return repository.EventParticipantModel{}, false, errors.New("ticket revoked")
+	}
+
+	return s.eventRepo.CheckIn(payload.UserID, eventID, organizerID)
+}
+
+func (s *EventService) Attendance(userID, eventID uint) ([]repository.EventParticipantModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetAttendance(eventID)
+}
+
+// OrganizationAttendance - права проверяет middleware маршрута
+func (s *EventService) OrganizationAttendance(orgID uint) ([]repository.AttendanceRow, error) {
+	return s.eventRepo.GetOrganizationAttendance(orgID)
+}
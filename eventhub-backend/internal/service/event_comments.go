@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxCommentLength     = 2000
+	defaultCommentsLimit = 20
+	maxCommentsLimit     = 100
+)
+
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.-]+)`)
+
+// CommentsPage - страница комментариев; NextCursor == 0 - больше комментариев нет
+type CommentsPage struct {
+	Comments   []repository.CommentRow
+	NextCursor uint
+}
+
+func validateComment(body string) (string, error) {
+	var validation domain.ValidationError
+
+	body = strings.TrimSpace(body)
+	switch {
+	case body == "":
+		validation.Add("body", "Комментарий не может быть пустым")
+	case utf8.RuneCountInString(body) > maxCommentLength:
+		validation.Add("body", fmt.Sprintf("Комментарий не должен быть длиннее %d символов", maxCommentLength))
+	}
+
+	return body, validation.Err()
+}
+
+func (s *EventService) Comments(userID, eventID uint, parentID *uint, cursor uint, limit int) (CommentsPage, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return CommentsPage{}, err
+	}
+
+	if parentID != nil {
+		if _, err := s.eventRepo.GetComment(eventID, *parentID); err != nil {
+			return CommentsPage{}, err
+		}
+	}
+
+	if limit <= 0 || limit > maxCommentsLimit {
+		limit = defaultCommentsLimit
+	}
+
+	// лишний комментарий показывает, есть ли следующая страница
+	rows, err := s.eventRepo.GetComments(eventID, parentID, cursor, limit+1)
+	if err != nil {
+		return CommentsPage{}, err
+	}
+
+	page := CommentsPage{Comments: rows}
+	if len(rows) > limit {
+		page.Comments = rows[:limit]
+		page.NextCursor = rows[limit-1].ID
+	}
+
+	return page, nil
+}
+
+func (s *EventService) CreateComment(userID, eventID uint, input domain.CommentInput) (repository.EventCommentModel, error) {
+	body, err := validateComment(input.Body)
+	if err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanCommentEvent); err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	if input.ParentID != nil {
+		parent, err := s.eventRepo.GetComment(eventID, *input.ParentID)
+		if err != nil {
+			return repository.EventCommentModel{}, err
+		}
+		if parent.DeletedAt != nil {
+			return repository.EventCommentModel{}, errors.New("comment deleted")
+		}
+	}
+
+	mentioned, err := s.mentionedUsers(userID, eventID, body, nil)
+	if err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	comment := repository.EventCommentModel{EventID: eventID, ParentID: input.ParentID, UserID: userID, Body: body}
+	if err := s.eventRepo.CreateComment(&comment, mentioned); err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	return comment, nil
+}
+
+// UpdateComment - менять текст может только автор
+func (s *EventService) UpdateComment(userID, eventID, commentID uint, input domain.CommentInput) (repository.EventCommentModel, error) {
+	body, err := validateComment(input.Body)
+	if err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanCommentEvent); err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	comment, err := s.eventRepo.GetComment(eventID, commentID)
+	if err != nil {
+		return repository.EventCommentModel{}, err
+	}
+	if comment.DeletedAt != nil {
+		return repository.EventCommentModel{}, errors.New("comment deleted")
+	}
+	if comment.UserID != userID {
+		return repository.EventCommentModel{}, &policy.ForbiddenError{Action: "edit_comment"}
+	}
+
+	mentioned, err := s.mentionedUsers(userID, eventID, body, mentionNames(comment.Body))
+	if err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	comment.Body = body
+	if err := s.eventRepo.UpdateComment(&comment, mentioned); err != nil {
+		return repository.EventCommentModel{}, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment - удалить может автор или организатор мероприятия
+func (s *EventService) DeleteComment(userID, eventID, commentID uint) error {
+	comment, err := s.eventRepo.GetComment(eventID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.DeletedAt != nil {
+		return errors.New("comment deleted")
+	}
+
+	check := policy.CanCommentEvent
+	if comment.UserID != userID {
+		check = policy.CanModerateComments
+	}
+	if err := s.authorize(userID, eventID, check); err != nil {
+		return err
+	}
+
+	return s.eventRepo.DeleteComment(commentID, userID)
+}
+
+// mentionedUsers находит упомянутых пользователей, которые видят мероприятие.
+// Имена из skip уже были упомянуты раньше и повторно не уведомляются.
+func (s *EventService) mentionedUsers(authorID, eventID uint, body string, skip map[string]bool) ([]uint, error) {
+	var usernames []string
+	for name := range mentionNames(body) {
+		if !skip[name] {
+			usernames = append(usernames, name)
+		}
+	}
+
+	userIDs, err := s.eventRepo.GetUserIDsByUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	mentioned := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == authorID {
+			continue
+		}
+		if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+			if policy.IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+		mentioned = append(mentioned, userID)
+	}
+
+	return mentioned, nil
+}
+
+func mentionNames(body string) map[string]bool {
+	names := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// точка в конце предложения не относится к имени
+		name := strings.TrimRight(match[1], ".")
+		if name != "" {
+			names[name] = true
+		}
+	}
+
+	return names
+}
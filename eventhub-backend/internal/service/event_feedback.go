@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxFeedbackCommentLength = 2000
+
+// SubmitFeedback принимает один отзыв от участника завершенного мероприятия
+func (s *EventService) SubmitFeedback(userID, eventID uint, input domain.FeedbackInput) error {
+	var validation domain.ValidationError
+	if input.Rating < 1 || input.Rating > 5 {
+		validation.Add("rating", "Оценка должна быть от 1 до 5")
+	}
+	input.Comment = strings.TrimSpace(input.Comment)
+	if utf8.RuneCountInString(input.Comment) > maxFeedbackCommentLength {
+		validation.Add("comment", fmt.Sprintf("Отзыв не должен быть длиннее %d символов", maxFeedbackCommentLength))
+	}
+	if err := validation.Err(); err != nil {
+		return err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event.Status != domain.EventStatusCompleted {
+		return errors.New("event not completed")
+	}
+
+	joined, err := s.eventRepo.IsUserJoined(userID, eventID)
+	if err != nil {
+		return err
+	}
+	if !joined {
+		return errors.New("user not participant")
+	}
+
+	return s.eventRepo.CreateFeedback(&repository.EventFeedbackModel{
+		EventID: eventID,
+		UserID:  userID,
+		Rating:  input.Rating,
+		Comment: input.Comment,
+	})
+}
+
+func (s *EventService) HasFeedback(userID, eventID uint) (bool, error) {
+	return s.eventRepo.HasFeedback(userID, eventID)
+}
+
+// Feedback - отзывы и сводка оценок для организаторов
+func (s *EventService) Feedback(userID, eventID uint) (repository.FeedbackSummary, []repository.EventFeedbackModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return repository.FeedbackSummary{}, nil, err
+	}
+
+	feedback, err := s.eventRepo.GetFeedback(eventID)
+	if err != nil {
+		return repository.FeedbackSummary{}, nil, err
+	}
+
+	return repository.SummarizeFeedback(feedback), feedback, nil
+}
+
+// OrganizationFeedback - права проверяет middleware маршрута
+func (s *EventService) OrganizationFeedback(orgID uint) (repository.FeedbackSummary, []repository.EventFeedbackRow, error) {
+	return s.eventRepo.GetOrganizationFeedback(orgID)
+}
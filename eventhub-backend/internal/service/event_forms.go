@@ -0,0 +1,247 @@
+package service
+
+import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxFormFields        = 30
+	maxFormLabelLength   = 200
+	maxFormOptions       = 50
+	maxFormAnswerLength  = 2000
+	maxFormOptionsLength = 200
+)
+
+// Form - анкета видна всем, кто видит мероприятие, чтобы заполнить ее при записи
+func (s *EventService) Form(userID, eventID uint) ([]repository.EventFormFieldModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetFormFields(eventID)
+}
+
+func (s *EventService) UpdateForm(userID, eventID uint, input domain.FormInput) ([]repository.EventFormFieldModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return nil, err
+	}
+
+	current, err := s.eventRepo.GetFormFields(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := validateFormInput(input, current)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventRepo.SaveFormFields(eventID, fields); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetFormFields(eventID)
+}
+
+// FormAnswers - ответы всех записавшихся, доступны организаторам
+func (s *EventService) FormAnswers(userID, eventID uint) ([]repository.EventFormFieldModel, []repository.FormAnswerRow, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return nil, nil, err
+	}
+
+	fields, err := s.eventRepo.GetFormFields(eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.eventRepo.GetFormAnswers(eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fields, rows, nil
+}
+
+func validateFormInput(input domain.FormInput, current []repository.EventFormFieldModel) ([]repository.EventFormFieldModel, error) {
+	var validation domain.ValidationError
+	if len(input.Fields) > maxFormFields {
+		validation.Add("fields", fmt.Sprintf("В анкете может быть не больше %d полей", maxFormFields))
+		return nil, validation.Err()
+	}
+
+	existing := make(map[uint]bool, len(current))
+	for _, field := range current {
+		existing[field.ID] = true
+	}
+
+	fields := make([]repository.EventFormFieldModel, 0, len(input.Fields))
+	seen := make(map[uint]bool, len(input.Fields))
+	for i, in := range input.Fields {
+		prefix := fmt.Sprintf("fields[%d].", i)
+
+		field := repository.EventFormFieldModel{
+			Label:    strings.TrimSpace(in.Label),
+			Kind:     in.Kind,
+			Required: in.Required,
+		}
+		if in.ID != nil {
+			if !existing[*in.ID] || seen[*in.ID] {
+				validation.Add(prefix+"id", "Поле не найдено в анкете")
+			}
+			seen[*in.ID] = true
+			field.ID = *in.ID
+		}
+
+		if field.Label == "" {
+			validation.Add(prefix+"label", "Название поля не может быть пустым")
+		} else if utf8.RuneCountInString(field.Label) > maxFormLabelLength {
+			validation.Add(prefix+"label", fmt.Sprintf("Название поля не должно быть длиннее %d символов", maxFormLabelLength))
+		}
+
+		switch in.Kind {
+		case domain.FormFieldText:
+			if in.MaxLength != nil && (*in.MaxLength < 1 || *in.MaxLength > maxFormAnswerLength) {
+				validation.Add(prefix+"max_length", fmt.Sprintf("Длина ответа должна быть от 1 до %d символов", maxFormAnswerLength))
+			}
+			field.MaxLength = in.MaxLength
+		case domain.FormFieldChoice:
+			options := make([]string, 0, len(in.Options))
+			unique := make(map[string]bool, len(in.Options))
+			for _, option := range in.Options {
+				option = strings.TrimSpace(option)
+				if option == "" || unique[option] {
+					continue
+				}
+				if utf8.RuneCountInString(option) > maxFormOptionsLength {
+					validation.Add(prefix+"options", fmt.Sprintf("Вариант не должен быть длиннее %d символов", maxFormOptionsLength))
+				}
+				unique[option] = true
+				options = append(options, option)
+			}
+			if len(options) < 2 || len(options) > maxFormOptions {
+				validation.Add(prefix+"options", fmt.Sprintf("Нужно от 2 до %d разных вариантов", maxFormOptions))
+			}
+			field.Options = options
+		case domain.FormFieldCheckbox:
+		case domain.FormFieldNumber:
+			if in.Min != nil && in.Max != nil && *in.Min > *in.Max {
+				validation.Add(prefix+"max", "Максимум не может быть меньше минимума")
+			}
+			field.Min = in.Min
+			field.Max = in.Max
+		default:
+			validation.Add(prefix+"kind", "Тип поля должен быть text, choice, checkbox или number")
+		}
+
+		fields = append(fields, field)
+	}
+
+	if err := validation.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// validateAnswers проверяет ответы по анкете и приводит их к типам полей.
+// Ответы на несуществующие поля отбрасываются; без анкеты возвращается nil.
+func validateAnswers(fields []repository.EventFormFieldModel, answers map[string]interface{}) (repository.FormAnswers, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var validation domain.ValidationError
+	result := make(repository.FormAnswers, len(fields))
+	for _, field := range fields {
+		key := strconv.FormatUint(uint64(field.ID), 10)
+		name := "answers." + key
+
+		value, ok := answers[key]
+		if ok && value == nil {
+			ok = false
+		}
+
+		switch field.Kind {
+		case domain.FormFieldText, domain.FormFieldChoice:
+			text, isString := value.(string)
+			if ok && !isString {
+				validation.Add(name, "Ответ должен быть строкой")
+				continue
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				if field.Required {
+					validation.Add(name, "Обязательное поле")
+				}
+				continue
+			}
+			if field.Kind == domain.FormFieldChoice && !containsString(field.Options, text) {
+				validation.Add(name, "Выберите один из вариантов")
+				continue
+			}
+			limit := maxFormAnswerLength
+			if field.MaxLength != nil {
+				limit = *field.MaxLength
+			}
+			if utf8.RuneCountInString(text) > limit {
+				validation.Add(name, fmt.Sprintf("Ответ не должен быть длиннее %d символов", limit))
+				continue
+			}
+			result[key] = text
+		case domain.FormFieldCheckbox:
+			checked, isBool := value.(bool)
+			if ok && !isBool {
+				validation.Add(name, "Ответ должен быть true или false")
+				continue
+			}
+			// обязательный флажок - согласие, его нужно отметить
+			if field.Required && !checked {
+				validation.Add(name, "Обязательное поле")
+				continue
+			}
+			result[key] = checked
+		case domain.FormFieldNumber:
+			if !ok {
+				if field.Required {
+					validation.Add(name, "Обязательное поле")
+				}
+				continue
+			}
+			number, isNumber := value.(float64)
+			if !isNumber {
+				validation.Add(name, "Ответ должен быть числом")
+				continue
+			}
+			if field.Min != nil && number < *field.Min {
+				validation.Add(name, fmt.Sprintf("Число должно быть не меньше %g", *field.Min))
+				continue
+			}
+			if field.Max != nil && number > *field.Max {
+				validation.Add(name, fmt.Sprintf("Число должно быть не больше %g", *field.Max))
+				continue
+			}
+			result[key] = number
+		}
+	}
+
+	if err := validation.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+)
+
+// Hosts возвращает создателя мероприятия и его соорганизаторов
+func (s *EventService) Hosts(userID, eventID uint) (uint, []repository.EventHostModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return 0, nil, err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	hosts, err := s.eventRepo.GetHosts(eventID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return event.CreatorId, hosts, nil
+}
+
+// AddHost назначает соорганизатора из участников организации мероприятия
+func (s *EventService) AddHost(userID, eventID, hostID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanManageHosts); err != nil {
+		return err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event.CreatorId == hostID {
+		return errors.New("user is creator")
+	}
+
+	role, err := s.orgRepo.GetRole(event.OrganizationId, hostID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return errors.New("user not in organization")
+	}
+
+	return s.eventRepo.AddHost(eventID, hostID, userID)
+}
+
+// RemoveHost снимает соорганизатора; отказаться от роли он может и сам
+func (s *EventService) RemoveHost(userID, eventID, hostID uint) error {
+	if userID != hostID {
+		if err := s.authorize(userID, eventID, policy.CanManageHosts); err != nil {
+			return err
+		}
+	}
+
+	return s.eventRepo.RemoveHost(eventID, hostID)
+}
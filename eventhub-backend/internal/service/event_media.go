@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"eventhub-backend/internal/storage"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	maxCoverSize      = 5 << 20
+	maxAttachmentSize = 20 << 20
+	thumbnailWidth    = 480
+)
+
+// расширения по реальному типу файла, а не по имени от клиента
+var uploadExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// UploadCover заменяет обложку мероприятия; к ней сохраняется уменьшенная копия для списков
+func (s *EventService) UploadCover(userID, eventID uint, name string, size int64, body io.Reader) (repository.EventAttachmentModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+
+	data, contentType, err := readUpload(body, size, maxCoverSize)
+	if err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+	if !storage.IsImageType(contentType) {
+		return repository.EventAttachmentModel{}, errors.New("unsupported file type")
+	}
+
+	cover, err := s.storeUpload(userID, eventID, repository.AttachmentCover, name, contentType, data)
+	if err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+
+	previous, err := s.eventRepo.SetCover(eventID, &cover)
+	if err != nil {
+		s.deleteBlobs([]repository.EventAttachmentModel{cover})
+		return repository.EventAttachmentModel{}, err
+	}
+	s.deleteBlobs(previous)
+
+	return cover, nil
+}
+
+func (s *EventService) DeleteCover(userID, eventID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	previous, err := s.eventRepo.SetCover(eventID, nil)
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(previous)
+
+	return nil
+}
+
+// UploadAttachment прикрепляет к мероприятию картинку или PDF
+func (s *EventService) UploadAttachment(userID, eventID uint, name string, size int64, body io.Reader) (repository.EventAttachmentModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+
+	data, contentType, err := readUpload(body, size, maxAttachmentSize)
+	if err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+	if _, ok := uploadExtensions[contentType]; !ok {
+		return repository.EventAttachmentModel{}, errors.New("unsupported file type")
+	}
+
+	attachment, err := s.storeUpload(userID, eventID, repository.AttachmentFile, name, contentType, data)
+	if err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+
+	if err := s.eventRepo.CreateAttachment(&attachment); err != nil {
+		s.deleteBlobs([]repository.EventAttachmentModel{attachment})
+		return repository.EventAttachmentModel{}, err
+	}
+
+	return attachment, nil
+}
+
+func (s *EventService) DeleteAttachment(userID, eventID, attachmentID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	attachment, err := s.eventRepo.GetAttachment(eventID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.eventRepo.DeleteAttachment(attachment.ID); err != nil {
+		return err
+	}
+	s.deleteBlobs([]repository.EventAttachmentModel{attachment})
+
+	return nil
+}
+
+// readUpload читает файл целиком, не доверяя заявленному размеру, и
+// определяет тип по содержимому
+func readUpload(body io.Reader, size, limit int64) ([]byte, string, error) {
+	if size > limit {
+		return nil, "", errors.New("file too large")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > limit {
+		return nil, "", errors.New("file too large")
+	}
+	if len(data) == 0 {
+		return nil, "", errors.New("unsupported file type")
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return data, contentType, nil
+}
+
+// storeUpload кладет файл и, для картинок, его уменьшенную копию в хранилище
+func (s *EventService) storeUpload(userID, eventID uint, kind, name, contentType string, data []byte) (repository.EventAttachmentModel, error) {
+	var thumbnail []byte
+	if storage.IsImageType(contentType) {
+		var err error
+		thumbnail, err = storage.Thumbnail(data, thumbnailWidth)
+		if err != nil {
+			return repository.EventAttachmentModel{}, errors.New("invalid image")
+		}
+	}
+
+	ctx := context.Background()
+	prefix := fmt.Sprintf("events/%d/%s", eventID, randomName())
+
+	attachment := repository.EventAttachmentModel{
+		EventID:     eventID,
+		Kind:        kind,
+		Name:        filepath.Base(name),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         prefix + uploadExtensions[contentType],
+		UploadedBy:  userID,
+	}
+	if err := s.blobStore.Put(ctx, attachment.Key, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return repository.EventAttachmentModel{}, err
+	}
+	attachment.URL = s.blobStore.URL(attachment.Key)
+
+	if thumbnail != nil {
+		attachment.ThumbKey = prefix + "_thumb.jpg"
+		if err := s.blobStore.Put(ctx, attachment.ThumbKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+			s.deleteBlobs([]repository.EventAttachmentModel{{Key: attachment.Key}})
+			return repository.EventAttachmentModel{}, err
+		}
+		attachment.ThumbURL = s.blobStore.URL(attachment.ThumbKey)
+	}
+
+	return attachment, nil
+}
+
+// deleteBlobs удаляет файлы после того, как записи о них уже удалены; ошибка
+// оставляет в хранилище лишний файл, поэтому только логируется
+func (s *EventService) deleteBlobs(attachments []repository.EventAttachmentModel) {
+	ctx := context.Background()
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.Key, attachment.ThumbKey} {
+			if key == "" {
+				continue
+			}
+			if err := s.blobStore.Delete(ctx, key); err != nil {
+				log.Println("Ошибка при удалении файла", key, err)
+			}
+		}
+	}
+}
+
+func randomName() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
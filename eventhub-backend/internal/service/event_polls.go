@@ -0,0 +1,367 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 200
+	maxPollOptions        = 20
+)
+
+type PollOptionResult struct {
+	repository.EventPollOptionModel
+	Votes  int    `json:"votes"`
+	Voters []uint `json:"voters,omitempty"` // не заполняется в анонимных опросах
+}
+
+type PollResults struct {
+	repository.EventPollModel
+	Closed      bool               `json:"closed"`
+	Options     []PollOptionResult `json:"options"`
+	TotalVoters int                `json:"total_voters"`
+	MyVotes     []uint             `json:"my_votes"`
+}
+
+func validatePollInput(input *domain.PollInput) error {
+	var validation domain.ValidationError
+
+	input.Question = strings.TrimSpace(input.Question)
+	switch {
+	case input.Question == "":
+		validation.Add("question", "Вопрос обязателен")
+	case utf8.RuneCountInString(input.Question) > maxPollQuestionLength:
+		validation.Add("question", fmt.Sprintf("Вопрос не должен быть длиннее %d символов", maxPollQuestionLength))
+	}
+
+	if input.Kind == "" {
+		input.Kind = domain.PollSingle
+	}
+	if input.Kind != domain.PollSingle && input.Kind != domain.PollMultiple {
+		validation.Add("kind", "Вид опроса должен быть single или multiple")
+	}
+
+	if input.ClosesAt != nil && !input.ClosesAt.After(time.Now()) {
+		validation.Add("closes_at", "Время закрытия должно быть в будущем")
+	}
+
+	if len(input.Options) < 2 || len(input.Options) > maxPollOptions {
+		validation.Add("options", fmt.Sprintf("Нужно от 2 до %d вариантов", maxPollOptions))
+	}
+	for i := range input.Options {
+		option := &input.Options[i]
+		field := fmt.Sprintf("options[%d]", i)
+
+		option.Text = strings.TrimSpace(option.Text)
+		option.Location = strings.TrimSpace(option.Location)
+		switch {
+		case option.Text == "":
+			validation.Add(field, "Текст варианта обязателен")
+		case utf8.RuneCountInString(option.Text) > maxPollOptionLength:
+			validation.Add(field, fmt.Sprintf("Вариант не должен быть длиннее %d символов", maxPollOptionLength))
+		case option.EndsAt != nil && option.StartsAt == nil:
+			validation.Add(field, "Окончание задается только вместе с началом")
+		case option.EndsAt != nil && !option.EndsAt.After(*option.StartsAt):
+			validation.Add(field, "Окончание должно быть позже начала")
+		}
+	}
+
+	return validation.Err()
+}
+
+func (s *EventService) CreatePoll(userID, eventID uint, input domain.PollInput) (PollResults, error) {
+	if err := validatePollInput(&input); err != nil {
+		return PollResults{}, err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return PollResults{}, err
+	}
+
+	poll := repository.EventPollModel{
+		EventID:   eventID,
+		CreatorID: userID,
+		Question:  input.Question,
+		Kind:      input.Kind,
+		Anonymous: input.Anonymous,
+		ClosesAt:  input.ClosesAt,
+	}
+
+	options := make([]repository.EventPollOptionModel, 0, len(input.Options))
+	for _, option := range input.Options {
+		options = append(options, repository.EventPollOptionModel{
+			Text:     option.Text,
+			StartsAt: option.StartsAt,
+			EndsAt:   option.EndsAt,
+			Location: option.Location,
+		})
+	}
+
+	if err := s.eventRepo.CreatePoll(&poll, options); err != nil {
+		return PollResults{}, err
+	}
+
+	return s.pollResults(userID, poll)
+}
+
+func (s *EventService) Polls(userID, eventID uint) ([]PollResults, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return nil, err
+	}
+
+	polls, err := s.eventRepo.GetPolls(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PollResults, 0, len(polls))
+	for _, poll := range polls {
+		result, err := s.pollResults(userID, poll)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Poll возвращает текущие результаты опроса
+func (s *EventService) Poll(userID, eventID, pollID uint) (PollResults, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return PollResults{}, err
+	}
+
+	poll, err := s.eventRepo.GetPoll(eventID, pollID)
+	if err != nil {
+		return PollResults{}, err
+	}
+
+	return s.pollResults(userID, poll)
+}
+
+// Vote заменяет голос пользователя. Голосуют только участники, ответившие going или maybe.
+func (s *EventService) Vote(userID, eventID, pollID uint, input domain.VoteInput) (PollResults, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return PollResults{}, err
+	}
+
+	status, err := s.GetRSVP(userID, eventID)
+	if err != nil {
+		return PollResults{}, err
+	}
+	if status != domain.RSVPGoing && status != domain.RSVPMaybe {
+		return PollResults{}, errors.New("user not participant")
+	}
+
+	poll, err := s.eventRepo.GetPoll(eventID, pollID)
+	if err != nil {
+		return PollResults{}, err
+	}
+
+	options, err := s.eventRepo.GetPollOptions(pollID)
+	if err != nil {
+		return PollResults{}, err
+	}
+
+	if err := validateVote(poll, options, input.OptionIDs); err != nil {
+		return PollResults{}, err
+	}
+
+	if err := s.eventRepo.Vote(pollID, userID, input.OptionIDs); err != nil {
+		return PollResults{}, err
+	}
+
+	return s.pollResults(userID, poll)
+}
+
+func validateVote(poll repository.EventPollModel, options []repository.EventPollOptionModel, optionIDs []uint) error {
+	var validation domain.ValidationError
+
+	known := make(map[uint]bool, len(options))
+	for _, option := range options {
+		known[option.ID] = true
+	}
+
+	seen := make(map[uint]bool, len(optionIDs))
+	for _, optionID := range optionIDs {
+		if !known[optionID] || seen[optionID] {
+			validation.Add("option_ids", "Некорректный вариант ответа")
+		}
+		seen[optionID] = true
+	}
+
+	switch {
+	case len(optionIDs) == 0:
+		validation.Add("option_ids", "Выберите вариант ответа")
+	case poll.Kind == domain.PollSingle && len(optionIDs) > 1:
+		validation.Add("option_ids", "В этом опросе можно выбрать только один вариант")
+	}
+
+	return validation.Err()
+}
+
+// ClosePoll закрывает опрос. С apply победивший вариант переносит время и место
+// мероприятия через Update; true в ответе означает, что мероприятие изменилось.
+func (s *EventService) ClosePoll(userID, eventID, pollID uint, apply bool) (PollResults, bool, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return PollResults{}, false, err
+	}
+
+	poll, err := s.eventRepo.GetPoll(eventID, pollID)
+	if err != nil {
+		return PollResults{}, false, err
+	}
+	if poll.ClosedAt != nil {
+		return PollResults{}, false, errors.New("poll closed")
+	}
+
+	var applyWinner func(tx *gorm.DB) (*uint, error)
+	if apply {
+		// победитель считается под блокировкой опроса, когда голоса уже не меняются,
+		// а мероприятие меняется в той же транзакции, что и закрытие опроса
+		applyWinner = func(tx *gorm.DB) (*uint, error) {
+			txService := s.withTx(tx)
+
+			results, err := txService.pollResults(userID, poll)
+			if err != nil {
+				return nil, err
+			}
+
+			winner, err := pollWinner(results.Options)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := txService.applyPollOption(userID, eventID, winner); err != nil {
+				return nil, err
+			}
+			return &winner.ID, nil
+		}
+	}
+
+	if err := s.eventRepo.ClosePoll(pollID, applyWinner); err != nil {
+		return PollResults{}, false, err
+	}
+
+	poll, err = s.eventRepo.GetPoll(eventID, pollID)
+	if err != nil {
+		return PollResults{}, false, err
+	}
+
+	results, err := s.pollResults(userID, poll)
+	return results, apply, err
+}
+
+// pollWinner - вариант с наибольшим числом голосов; ничья не дает победителя
+func pollWinner(options []PollOptionResult) (repository.EventPollOptionModel, error) {
+	var winner *PollOptionResult
+	tie := false
+	for i := range options {
+		switch {
+		case winner == nil || options[i].Votes > winner.Votes:
+			winner = &options[i]
+			tie = false
+		case options[i].Votes == winner.Votes:
+			tie = true
+		}
+	}
+
+	if winner == nil || winner.Votes == 0 || tie {
+		return repository.EventPollOptionModel{}, errors.New("poll has no winner")
+	}
+
+	return winner.EventPollOptionModel, nil
+}
+
+func (s *EventService) applyPollOption(userID, eventID uint, option repository.EventPollOptionModel) error {
+	if option.StartsAt == nil && option.Location == "" {
+		return errors.New("poll option has nothing to apply")
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	input := domain.CreateEventInput{
+		Title:       event.Title,
+		Description: event.Description,
+		Category:    event.Category,
+		Location:    event.Location,
+		IsPublic:    &event.IsPublic,
+		StartsAt:    event.StartsAt,
+		EndsAt:      event.EndsAt,
+		Timezone:    event.Timezone,
+		Capacity:    event.Capacity,
+	}
+
+	if option.StartsAt != nil {
+		// без окончания сохраняется прежняя длительность
+		duration := event.EndsAt.Sub(event.StartsAt)
+		input.StartsAt = *option.StartsAt
+		input.EndsAt = option.StartsAt.Add(duration)
+		if option.EndsAt != nil {
+			input.EndsAt = *option.EndsAt
+		}
+	}
+	if option.Location != "" {
+		input.Location = option.Location
+	}
+
+	return s.Update(userID, eventID, event.OrganizationId, input, domain.ScopeThis)
+}
+
+func (s *EventService) pollResults(userID uint, poll repository.EventPollModel) (PollResults, error) {
+	options, err := s.eventRepo.GetPollOptions(poll.ID)
+	if err != nil {
+		return PollResults{}, err
+	}
+
+	votes, err := s.eventRepo.GetPollVotes(poll.ID)
+	if err != nil {
+		return PollResults{}, err
+	}
+
+	byOption := make(map[uint]*PollOptionResult, len(options))
+	results := PollResults{
+		EventPollModel: poll,
+		Closed:         poll.IsClosed(time.Now()),
+		Options:        make([]PollOptionResult, len(options)),
+		MyVotes:        []uint{},
+	}
+	for i, option := range options {
+		results.Options[i] = PollOptionResult{EventPollOptionModel: option}
+		byOption[option.ID] = &results.Options[i]
+	}
+
+	voters := make(map[uint]bool)
+	for _, vote := range votes {
+		option, ok := byOption[vote.OptionID]
+		if !ok {
+			continue
+		}
+
+		option.Votes++
+		if !poll.Anonymous {
+			option.Voters = append(option.Voters, vote.UserID)
+		}
+		voters[vote.UserID] = true
+		if vote.UserID == userID {
+			results.MyVotes = append(results.MyVotes, vote.OptionID)
+		}
+	}
+	results.TotalVoters = len(voters)
+
+	return results, nil
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	// вхождения серии создаются заранее на этот срок
+	seriesHorizon = 90 * 24 * time.Hour
+	// ограничение на число вхождений, создаваемых за один проход
+	seriesMaxOccurrences = 200
+)
+
+var seriesFrequencies = map[rrule.Frequency]bool{
+	rrule.DAILY:   true,
+	rrule.WEEKLY:  true,
+	rrule.MONTHLY: true,
+	rrule.YEARLY:  true,
+}
+
+// parseRRule разбирает правило повторения; допускаются только дневные и более редкие частоты
+func parseRRule(value string) (*rrule.ROption, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" || strings.Contains(value, "\n") {
+		return nil, errors.New("invalid rrule")
+	}
+
+	option, err := rrule.StrToROption(value)
+	if err != nil || !seriesFrequencies[option.Freq] {
+		return nil, errors.New("invalid rrule")
+	}
+
+	return option, nil
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// occurrenceDates разворачивает правило серии, начинающейся в start, в даты от from до to включительно
+func occurrenceDates(option rrule.ROption, start, from, to time.Time) ([]time.Time, error) {
+	option.Dtstart = dateOnly(start)
+
+	rule, err := rrule.NewRRule(option)
+	if err != nil {
+		return nil, errors.New("invalid rrule")
+	}
+
+	return rule.Between(dateOnly(from), dateOnly(to), true), nil
+}
+
+// expandDates - occurrenceDates с ограничением числа вхождений за один проход
+func expandDates(option rrule.ROption, start, from, to time.Time) ([]time.Time, error) {
+	dates, err := occurrenceDates(option, start, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dates) > seriesMaxOccurrences {
+		dates = dates[:seriesMaxOccurrences]
+	}
+
+	return dates, nil
+}
+
+// expandedUntil - до какой даты созданы вхождения после очередного разворачивания
+func expandedUntil(dates []time.Time, horizon time.Time) time.Time {
+	if len(dates) == seriesMaxOccurrences {
+		return dates[len(dates)-1]
+	}
+	return dateOnly(horizon)
+}
+
+// newSeries переносит поля мероприятия в серию; начало серии задается
+// местными датой и временем в часовом поясе мероприятия
+func newSeries(input domain.CreateEventInput) repository.EventSeriesModel {
+	startsAt := input.StartsAt.In(repository.LoadLocation(input.Timezone))
+
+	return repository.EventSeriesModel{
+		StartDate:   dateOnly(startsAt),
+		Title:       input.Title,
+		Description: input.Description,
+		Category:    input.Category,
+		IsPublic:    input.Public(),
+		StartTime:   startsAt.Format("15:04:05"),
+		Duration:    int64(input.EndsAt.Sub(input.StartsAt) / time.Second),
+		Timezone:    input.Timezone,
+		Location:    input.Location,
+		Capacity:    input.Capacity,
+	}
+}
+
+func (s *EventService) createSeries(input domain.CreateEventInput, creatorID, orgID uint) error {
+	option, err := parseRRule(input.RRule)
+	if err != nil {
+		return err
+	}
+
+	series := newSeries(input)
+	series.CreatorId = creatorID
+	series.OrganizationId = orgID
+
+	horizon := time.Now().Add(seriesHorizon)
+	dates, err := expandDates(*option, series.StartDate, series.StartDate, horizon)
+	if err != nil {
+		return err
+	}
+	if len(dates) == 0 {
+		return &domain.ValidationError{Fields: map[string]string{"rrule": "Правило повторения не дает ни одной даты"}}
+	}
+
+	series.RRule = option.RRuleString()
+	series.ExpandedUntil = expandedUntil(dates, horizon)
+
+	return s.eventRepo.CreateSeries(&series, dates)
+}
+
+// truncatedRRule обрезает правило серии так, чтобы последнее вхождение было до from
+func truncatedRRule(option rrule.ROption, from time.Time) string {
+	option.Count = 0
+	option.Until = dateOnly(from).AddDate(0, 0, -1)
+	return option.RRuleString()
+}
+
+// updateFollowing применяет изменения к вхождению и всем следующим за ним.
+// Вхождения отделяются в новую серию, прошедшие остаются в старой.
+func (s *EventService) updateFollowing(current repository.EventModel, input domain.CreateEventInput, userID uint) error {
+	series, err := s.eventRepo.GetSeries(*current.SeriesId)
+	if err != nil {
+		return err
+	}
+
+	option, err := parseRRule(series.RRule)
+	if err != nil {
+		return err
+	}
+
+	next := newSeries(input)
+	next.CreatorId = series.CreatorId
+	next.OrganizationId = series.OrganizationId
+
+	from := dateOnly(*current.RecurrenceDate)
+	start := next.StartDate
+	shiftDays := int(start.Sub(from).Hours() / 24)
+	next.ExpandedUntil = dateOnly(series.ExpandedUntil).AddDate(0, 0, shiftDays)
+
+	if input.RRule != "" {
+		nextOption, err := parseRRule(input.RRule)
+		if err != nil {
+			return err
+		}
+
+		if nextOption.RRuleString() != series.RRule {
+			horizon := time.Now().Add(seriesHorizon)
+			dates, err := expandDates(*nextOption, start, start, horizon)
+			if err != nil {
+				return err
+			}
+
+			next.RRule = nextOption.RRuleString()
+			next.ExpandedUntil = expandedUntil(dates, horizon)
+			return s.eventRepo.ReplaceFollowing(series.ID, from, truncatedRRule(*option, from), &next, dates)
+		}
+	}
+
+	// правило то же: остаток серии продолжается с новой даты
+	nextOption := *option
+	if option.Count > 0 {
+		passed, err := occurrenceDates(*option, series.StartDate, series.StartDate, from.AddDate(0, 0, -1))
+		if err != nil {
+			return err
+		}
+		nextOption.Count = option.Count - len(passed)
+	}
+	if !option.Until.IsZero() {
+		nextOption.Until = option.Until.AddDate(0, 0, shiftDays)
+	}
+	// дни недели сдвигаются вместе с вхождениями, а отсчет (DTSTART) новой
+	// серии идет от ее StartDate, то есть от новой даты вхождения
+	nextOption.Byweekday = shiftWeekdays(option.Byweekday, shiftDays)
+	nextOption.Dtstart = start
+	next.RRule = nextOption.RRuleString()
+
+	return s.eventRepo.SplitSeries(series.ID, from, truncatedRRule(*option, from), &next, shiftDays, userID)
+}
+
+var weekdays = []rrule.Weekday{rrule.MO, rrule.TU, rrule.WE, rrule.TH, rrule.FR, rrule.SA, rrule.SU}
+
+// shiftWeekdays сдвигает дни BYDAY на days дней, сохраняя номер (2MO -> 2TU)
+func shiftWeekdays(days []rrule.Weekday, shift int) []rrule.Weekday {
+	if len(days) == 0 {
+		return nil
+	}
+
+	shifted := make([]rrule.Weekday, len(days))
+	for i, day := range days {
+		weekday := weekdays[((day.Day()+shift)%7+7)%7]
+		if day.N() != 0 {
+			weekday = weekday.Nth(day.N())
+		}
+		shifted[i] = weekday
+	}
+
+	return shifted
+}
+
+func (s *EventService) cancelFollowing(current repository.EventModel, userID uint) error {
+	series, err := s.eventRepo.GetSeries(*current.SeriesId)
+	if err != nil {
+		return err
+	}
+
+	option, err := parseRRule(series.RRule)
+	if err != nil {
+		return err
+	}
+
+	return s.eventRepo.CancelFollowing(series.ID, *current.RecurrenceDate, truncatedRRule(*option, *current.RecurrenceDate), userID)
+}
+
+// OccurrenceIDs возвращает мероприятия, которые затронет изменение в области scope
+func (s *EventService) OccurrenceIDs(eventID uint, scope string) ([]uint, error) {
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope != domain.ScopeFollowing || event.SeriesId == nil {
+		return []uint{eventID}, nil
+	}
+
+	return s.eventRepo.GetFollowingIDs(*event.SeriesId, *event.RecurrenceDate)
+}
+
+func (s *EventService) seriesEvent(eventID uint) (repository.EventModel, error) {
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil || event.Status == domain.EventStatusDeleted {
+		return repository.EventModel{}, errors.New("event not found")
+	}
+	if event.SeriesId == nil {
+		return repository.EventModel{}, errors.New("event is not recurring")
+	}
+
+	return event, nil
+}
+
+// JoinSeries записывает пользователя на все будущие вхождения серии, включая еще не созданные
+func (s *EventService) JoinSeries(userID, eventID uint) error {
+	event, err := s.seriesEvent(eventID)
+	if err != nil {
+		return err
+	}
+
+	actor, err := s.actor(userID, event)
+	if err != nil {
+		return err
+	}
+	if err := policy.CanJoinEvent(actor, policyEvent(event)); err != nil {
+		return err
+	}
+
+	return s.eventRepo.JoinSeries(userID, *event.SeriesId, time.Now())
+}
+
+func (s *EventService) QuitSeries(userID, eventID uint) error {
+	event, err := s.seriesEvent(eventID)
+	if err != nil {
+		return err
+	}
+
+	return s.eventRepo.QuitSeries(userID, *event.SeriesId, time.Now())
+}
+
+func (s *EventService) IsUserSubscribed(userID, eventID uint) (bool, error) {
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return false, err
+	}
+	if event.SeriesId == nil {
+		return false, nil
+	}
+
+	return s.eventRepo.IsUserSubscribed(userID, *event.SeriesId)
+}
+
+func (s *EventService) StartSeriesExpander() {
+	ticker := time.NewTicker(1 * time.Hour)
+
+	go func() {
+		for range ticker.C {
+			err := s.expandSeries()
+			if err != nil {
+				log.Println("Ошибка при создании вхождений серий:", err)
+			}
+		}
+	}()
+}
+
+// expandSeries создает вхождения серий вперед до горизонта
+func (s *EventService) expandSeries() error {
+	horizon := time.Now().Add(seriesHorizon)
+
+	seriesList, err := s.eventRepo.GetSeriesToExpand(dateOnly(horizon))
+	if err != nil {
+		return err
+	}
+
+	for _, series := range seriesList {
+		option, err := parseRRule(series.RRule)
+		if err != nil {
+			log.Printf("Некорректное правило серии %d: %v", series.ID, err)
+			continue
+		}
+
+		dates, err := expandDates(*option, series.StartDate, dateOnly(series.ExpandedUntil).AddDate(0, 0, 1), horizon)
+		if err != nil {
+			return err
+		}
+
+		if err := s.eventRepo.AddOccurrences(series, dates, expandedUntil(dates, horizon)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
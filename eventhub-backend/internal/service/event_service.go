@@ -3,17 +3,38 @@ package service
 import (
 	"errors"
 	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
 	"eventhub-backend/internal/repository"
+	"eventhub-backend/internal/search"
+	"eventhub-backend/internal/storage"
+	"eventhub-backend/pkg/ticket"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
 )
 
 type EventService struct {
-	eventRepo repository.GormEventRepository
-	orgRepo   repository.GormOrganizationRepository
+	eventRepo      repository.GormEventRepository
+	orgRepo        repository.GormOrganizationRepository
+	checkpointRepo repository.GormSearchCheckpointRepository
+	searcher       search.EventSearcher
+	ticketSigner   *ticket.Signer
+	blobStore      storage.BlobStore
+}
+
+func NewEventService(eventRepo repository.GormEventRepository, orgRepo repository.GormOrganizationRepository, checkpointRepo repository.GormSearchCheckpointRepository, searcher search.EventSearcher, ticketSigner *ticket.Signer, blobStore storage.BlobStore) *EventService {
+	return &EventService{eventRepo: eventRepo, orgRepo: orgRepo, checkpointRepo: checkpointRepo, searcher: searcher, ticketSigner: ticketSigner, blobStore: blobStore}
 }
 
-func NewEventService(eventRepo repository.GormEventRepository, orgRepo repository.GormOrganizationRepository) *EventService {
-	return &EventService{eventRepo: eventRepo, orgRepo: orgRepo}
+// withTx - копия сервиса, репозитории которой работают внутри транзакции tx
+func (s *EventService) withTx(tx *gorm.DB) *EventService {
+	txService := *s
+	txService.eventRepo = *repository.NewGormEventRepository(tx)
+	txService.orgRepo = *repository.NewGormOrganizationRepository(tx)
+	return &txService
 }
 
 func (s *EventService) GetAllUser(userID uint) ([]repository.EventResponse, []repository.EventResponse, []repository.EventResponse, error) {
@@ -30,24 +51,70 @@ func (s *EventService) GetAllUser(userID uint) ([]repository.EventResponse, []re
 	return s.eventRepo.GetAllUser(userID, userJoined, userCreator)
 }
 
-func (s *EventService) Join(userID, eventID uint) error {
+func (s *EventService) Search(userID uint, params domain.EventSearchParams) ([]search.Hit, int, error) {
+	userJoined, err := s.orgRepo.GetUserJoined(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userCreator, err := s.orgRepo.GetUserCreator(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	access := search.Access{UserID: userID, OrgIDs: append(userJoined, userCreator...)}
+	return s.searcher.Search(params, access)
+}
+
+// Join записывает пользователя и возвращает позицию в очереди, если свободных
+// мест не осталось. Если у мероприятия есть типы билетов, нужно выбрать один из
+// них, а если есть анкета - ответить на ее обязательные поля.
+func (s *EventService) Join(userID, eventID uint, input domain.JoinInput) (int, error) {
 	exists, err := s.eventRepo.IsEventExist(eventID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if !exists {
-		return errors.New("event not found")
+		return 0, errors.New("event not found")
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanJoinEvent); err != nil {
+		return 0, err
+	}
+	if err := s.checkRegistrationOpen(eventID); err != nil {
+		return 0, err
 	}
 
 	joined, err := s.eventRepo.IsUserJoined(userID, eventID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if joined {
-		return errors.New("user already joined")
+		return 0, errors.New("user already joined")
 	}
 
-	return s.eventRepo.Join(userID, eventID)
+	waitlisted, err := s.eventRepo.IsUserWaitlisted(userID, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if waitlisted {
+		return 0, errors.New("user already waitlisted")
+	}
+
+	if err := s.checkTicketType(eventID, input.TicketTypeID); err != nil {
+		return 0, err
+	}
+
+	fields, err := s.eventRepo.GetFormFields(eventID)
+	if err != nil {
+		return 0, err
+	}
+	answers, err := validateAnswers(fields, input.Answers)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.eventRepo.Join(userID, eventID, input.TicketTypeID, answers)
 }
 
 func (s *EventService) Quit(userID, eventID uint) error {
@@ -59,65 +126,266 @@ func (s *EventService) Quit(userID, eventID uint) error {
 		return errors.New("event not found")
 	}
 
-	joined, err := s.eventRepo.IsUserJoined(userID, eventID)
+	return s.eventRepo.Quit(userID, eventID)
+}
+
+// checkRegistrationOpen не дает записаться на отмененное или завершенное мероприятие
+func (s *EventService) checkRegistrationOpen(eventID uint) error {
+	event, err := s.eventRepo.GetEventModelByID(eventID)
 	if err != nil {
 		return err
 	}
-	if !joined {
-		return errors.New("user not joined")
+	if !domain.IsRegistrationOpen(event.Status) {
+		return errors.New("registration closed")
 	}
 
-	return s.eventRepo.Quit(userID, eventID)
+	return nil
+}
+
+// GetAvailability возвращает число свободных мест (-1 - без ограничений)
+// и позицию пользователя в очереди (0 - не в очереди)
+func (s *EventService) GetAvailability(userID, eventID uint) (int, int, error) {
+	freeSeats, err := s.eventRepo.GetFreeSeats(eventID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	position, err := s.eventRepo.GetWaitlistPosition(userID, eventID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return freeSeats, position, nil
+}
+
+const (
+	maxEventDuration = 31 * 24 * time.Hour
+	maxTitleLength   = 200
+)
+
+// validateEventInput проверяет мероприятие и возвращает ошибки по полям;
+// пустой часовой пояс считается UTC
+func validateEventInput(input *domain.CreateEventInput, isNew bool) error {
+	var validation domain.ValidationError
+
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		validation.Add("title", "Укажите название мероприятия")
+	} else if utf8.RuneCountInString(input.Title) > maxTitleLength {
+		validation.Add("title", fmt.Sprintf("Название не должно быть длиннее %d символов", maxTitleLength))
+	}
+
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil || input.Timezone == "Local" {
+		validation.Add("timezone", "Неизвестный часовой пояс")
+	}
+
+	if input.StartsAt.IsZero() {
+		validation.Add("starts_at", "Укажите время начала")
+	} else if isNew && input.StartsAt.Before(time.Now()) {
+		validation.Add("starts_at", "Время начала уже прошло")
+	}
+
+	if input.EndsAt.IsZero() {
+		validation.Add("ends_at", "Укажите время окончания")
+	} else if !input.StartsAt.IsZero() {
+		if !input.EndsAt.After(input.StartsAt) {
+			validation.Add("ends_at", "Окончание должно быть позже начала")
+		} else if input.EndsAt.Sub(input.StartsAt) > maxEventDuration {
+			validation.Add("ends_at", "Мероприятие не может длиться дольше 31 дня")
+		}
+	}
+
+	if input.Capacity != nil && *input.Capacity < 1 {
+		validation.Add("capacity", "Количество мест должно быть положительным")
+	}
+
+	if input.RRule != "" {
+		if _, err := parseRRule(input.RRule); err != nil {
+			validation.Add("rrule", "Некорректное правило повторения")
+		}
+		if isNew && (input.Draft || input.PublishAt != nil) {
+			validation.Add("rrule", "Повторяющиеся мероприятия публикуются сразу, без черновика и отложенной публикации")
+		}
+		if isNew && input.TemplateID != nil {
+			validation.Add("rrule", "Шаблон нельзя использовать для повторяющихся мероприятий")
+		}
+	}
+
+	if isNew && input.PublishAt != nil && !input.StartsAt.IsZero() && !input.PublishAt.Before(input.StartsAt) {
+		validation.Add("publish_at", "Публикация должна быть раньше начала мероприятия")
+	}
+
+	return validation.Err()
 }
 
 func (s *EventService) Create(input domain.CreateEventInput, creatorID, orgID uint) error {
-	today := time.Now().Truncate(24 * time.Hour)
-	if input.Date.Before(today) {
-		return errors.New("дата в прошлом")
+	var template *repository.EventTemplateModel
+	if input.TemplateID != nil {
+		found, err := s.eventRepo.GetTemplate(orgID, *input.TemplateID)
+		if err != nil {
+			return err
+		}
+		template = &found
+	}
+
+	var extras repository.EventCopy
+	var files []repository.EventAttachmentModel
+	if template != nil {
+		extras, files = applyTemplate(&input, *template)
+	}
+
+	if err := validateEventInput(&input, true); err != nil {
+		return err
+	}
+
+	if template != nil {
+		_, err := s.createCopy(input, creatorID, orgID, extras, files)
+		return err
 	}
 
-	event, err := s.eventRepo.Create(input, creatorID, orgID)
+	status, err := s.createStatus(input, orgID, creatorID)
 	if err != nil {
 		return err
 	}
 
-	go createEventDocInElastic(event)
+	if input.RRule != "" {
+		// вхождения серии создаются сразу активными, одобрить их по одному нельзя
+		if status != domain.EventStatusActive {
+			var validation domain.ValidationError
+			validation.Add("rrule", "В этой организации повторяющиеся мероприятия создают только администраторы")
+			return validation.Err()
+		}
+		return s.createSeries(input, creatorID, orgID)
+	}
 
-	return nil
+	_, err = s.eventRepo.Create(input, creatorID, orgID, status)
+	return err
+}
+
+// createStatus - начальный статус нового мероприятия
+func (s *EventService) createStatus(input domain.CreateEventInput, orgID, creatorID uint) (string, error) {
+	if input.Draft {
+		return domain.EventStatusDraft, nil
+	}
+
+	return s.publishStatus(orgID, creatorID, input.PublishAt)
 }
 
-func (s *EventService) GetByID(eventID, userID uint) (repository.EventResponse, bool, error) {
-	return s.eventRepo.GetByID(eventID, userID)
+func (s *EventService) GetByID(eventID, userID uint) (repository.EventResponse, string, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return repository.EventResponse{}, "", err
+	}
+
+	response, role, err := s.eventRepo.GetByID(eventID, userID)
+	if err != nil || role != domain.EventRoleHost {
+		return response, role, err
+	}
+
+	// как и в actor: вышедший из организации соорганизатор теряет роль
+	orgRole, err := s.orgRepo.GetRole(response.OrganizationId, userID)
+	if err != nil {
+		return repository.EventResponse{}, "", err
+	}
+	if orgRole == "" {
+		role = ""
+	}
+
+	return response, role, nil
 }
 
 func (s *EventService) IsUserJoined(userID, eventID uint) (bool, error) {
 	return s.eventRepo.IsUserJoined(userID, eventID)
 }
 
-func (s *EventService) IsUserCreator(userID, eventID uint) (bool, error) {
-	return s.eventRepo.IsUserCreator(userID, eventID)
+func (s *EventService) actor(userID uint, event repository.EventModel) (policy.Actor, error) {
+	role, err := s.orgRepo.GetRole(event.OrganizationId, userID)
+	if err != nil {
+		return policy.Actor{}, err
+	}
+
+	joined, err := s.eventRepo.IsUserJoined(userID, event.ID)
+	if err != nil {
+		return policy.Actor{}, err
+	}
+
+	host, err := s.eventRepo.IsUserHost(userID, event.ID)
+	if err != nil {
+		return policy.Actor{}, err
+	}
+
+	// запись в event_hosts остается после выхода из организации, но права
+	// соорганизатора есть только у ее участников
+	return policy.Actor{UserID: userID, OrgRole: role, Joined: joined, Host: host && role != ""}, nil
 }
 
-func (s *EventService) Delete(userID, eventID uint) error {
-	isCreator, err := s.eventRepo.IsUserCreator(userID, eventID)
+func policyEvent(event repository.EventModel) policy.Event {
+	return policy.Event{CreatorID: event.CreatorId, IsPublic: event.IsPublic, Status: event.Status}
+}
+
+// authorize загружает мероприятие и проверяет действие пользователя над ним
+func (s *EventService) authorize(userID, eventID uint, check func(policy.Actor, policy.Event) error) error {
+	event, err := s.eventRepo.GetEventModelByID(eventID)
 	if err != nil {
 		return err
 	}
 
-	if !isCreator {
-		return errors.New("access denied")
+	actor, err := s.actor(userID, event)
+	if err != nil {
+		return err
 	}
 
-	return s.eventRepo.Delete(eventID)
+	return check(actor, policyEvent(event))
 }
 
-func (s *EventService) Update(userID, eventID, orgID uint, input domain.CreateEventInput) error {
-	isCreator, err := s.eventRepo.IsUserCreator(userID, eventID)
+// CanEdit разрешает изменение создателю мероприятия, соорганизаторам и ролям
+// организации с правом редактировать любые мероприятия
+func (s *EventService) CanEdit(userID, eventID uint) (bool, error) {
+	err := s.authorize(userID, eventID, policy.CanEditEvent)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || policy.IsForbidden(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Delete отменяет мероприятие; для вхождения серии со scope following -
+// также все следующие вхождения
+func (s *EventService) Delete(userID, eventID uint, scope string) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
 		return err
 	}
-	if !isCreator {
-		return errors.New("access denied")
+
+	if scope == domain.ScopeFollowing {
+		current, err := s.eventRepo.GetEventModelByID(eventID)
+		if err != nil {
+			return err
+		}
+		if current.SeriesId != nil {
+			return s.cancelFollowing(current, userID)
+		}
+	}
+
+	return s.eventRepo.Delete(eventID, userID)
+}
+
+// Update изменяет мероприятие; для вхождения серии со scope following -
+// также все следующие вхождения, scope this меняет только одно вхождение
+func (s *EventService) Update(userID, eventID, orgID uint, input domain.CreateEventInput, scope string) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("event not exists")
+		}
+		return err
+	}
+
+	if err := validateEventInput(&input, false); err != nil {
+		return err
 	}
 
 	exists, err := s.eventRepo.IsEventExist(eventID)
@@ -136,45 +404,194 @@ func (s *EventService) Update(userID, eventID, orgID uint, input domain.CreateEv
 		return errors.New("event not exists in this organization")
 	}
 
-	event := repository.EventModel{
-		ID:             eventID,
-		Title:          input.Title,
-		Description:    input.Description,
-		Category:       input.Category,
-		IsPublic:       input.IsPublic,
-		Status:         "active",
-		Date:           input.Date,
-		StartTime:      input.StartTime,
-		EndTime:        input.EndTime,
-		Location:       input.Location,
-		CreatorId:      userID,
-		OrganizationId: orgID,
-	}
-
-	err = s.eventRepo.Update(&event)
+	current, err := s.eventRepo.GetEventModelByID(eventID)
 	if err != nil {
 		return err
 	}
+	if !domain.IsEditable(current.Status) {
+		return errors.New("event not editable")
+	}
+	// без is_public видимость не меняется
+	if input.IsPublic == nil {
+		input.IsPublic = &current.IsPublic
+	}
+	// без capacity число мест не меняется
+	if input.Capacity == nil {
+		input.Capacity = current.Capacity
+	}
 
-	go createEventDocInElastic(&event)
+	if scope == domain.ScopeFollowing && current.SeriesId != nil {
+		return s.updateFollowing(current, input, userID)
+	}
+	// правило повторения меняется только у серии, и только начиная с вхождения
+	if input.RRule != "" {
+		return &domain.ValidationError{Fields: map[string]string{"rrule": "Правило повторения можно изменить только для этого и следующих вхождений серии"}}
+	}
 
-	return nil
+	// статус, оценки и обложка меняются своими действиями и сохраняются как есть
+	event := current
+	event.Title = input.Title
+	event.Description = input.Description
+	event.Category = input.Category
+	event.IsPublic = input.Public()
+	event.StartsAt = input.StartsAt.UTC()
+	event.EndsAt = input.EndsAt.UTC()
+	event.Timezone = input.Timezone
+	event.Location = input.Location
+	event.OrganizationId = orgID
+	event.Capacity = input.Capacity
+
+	return s.eventRepo.Update(&event, userID)
 }
 
 func (s *EventService) GetParticipantIDs(eventID uint) ([]uint, error) {
 	return s.eventRepo.GetParticipantIDs(eventID)
 }
 
+const maxRSVPNoteLength = 500
+
+// SetRSVP сохраняет ответ на приглашение и возвращает позицию в очереди,
+// если пользователь ответил going, а мест нет
+func (s *EventService) SetRSVP(userID, eventID uint, input domain.RSVPInput) (int, error) {
+	var validation domain.ValidationError
+	switch input.Status {
+	case domain.RSVPGoing, domain.RSVPMaybe, domain.RSVPDeclined:
+	default:
+		validation.Add("status", "Ответ должен быть going, maybe или declined")
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	if utf8.RuneCountInString(input.Note) > maxRSVPNoteLength {
+		validation.Add("note", fmt.Sprintf("Заметка не должна быть длиннее %d символов", maxRSVPNoteLength))
+	}
+	if err := validation.Err(); err != nil {
+		return 0, err
+	}
+
+	exists, err := s.eventRepo.IsEventExist(eventID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, errors.New("event not found")
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanJoinEvent); err != nil {
+		return 0, err
+	}
+
+	// билет с типом и анкету можно получить и заполнить только через Join
+	if input.Status == domain.RSVPGoing {
+		if err := s.checkRegistrationOpen(eventID); err != nil {
+			return 0, err
+		}
+
+		joined, err := s.eventRepo.IsUserJoined(userID, eventID)
+		if err != nil {
+			return 0, err
+		}
+		hasTypes, err := s.eventRepo.HasTicketTypes(eventID)
+		if err != nil {
+			return 0, err
+		}
+		if hasTypes && !joined {
+			return 0, errors.New("ticket type required")
+		}
+		fields, err := s.eventRepo.GetFormFields(eventID)
+		if err != nil {
+			return 0, err
+		}
+		if len(fields) > 0 && !joined {
+			return 0, errors.New("registration form required")
+		}
+	}
+
+	return s.eventRepo.SetRSVP(userID, eventID, input.Status, input.Note)
+}
+
+func (s *EventService) GetRSVP(userID, eventID uint) (string, error) {
+	response, found, err := s.eventRepo.GetRSVP(userID, eventID)
+	if err != nil || !found {
+		return "", err
+	}
+
+	return response.Status, nil
+}
+
+// Participants - ответы на приглашение. Responses и Waitlist заполняются
+// только для тех, кто может редактировать мероприятие.
+type Participants struct {
+	Going     []uint
+	Counts    map[string]int
+	Responses []repository.EventParticipantModel
+	Waitlist  []uint
+}
+
+func (s *EventService) ListParticipants(userID, eventID uint) (Participants, error) {
+	if err := s.authorize(userID, eventID, policy.CanListParticipants); err != nil {
+		return Participants{}, err
+	}
+
+	responses, err := s.eventRepo.GetResponses(eventID)
+	if err != nil {
+		return Participants{}, err
+	}
+
+	waitlist, err := s.eventRepo.GetWaitlistUserIDs(eventID)
+	if err != nil {
+		return Participants{}, err
+	}
+
+	participants := Participants{
+		Counts: map[string]int{
+			domain.RSVPGoing:    0,
+			domain.RSVPMaybe:    0,
+			domain.RSVPDeclined: 0,
+			"waitlisted":        len(waitlist),
+		},
+	}
+	for _, response := range responses {
+		participants.Counts[response.Status]++
+		if response.Status == domain.RSVPGoing {
+			participants.Going = append(participants.Going, response.UserID)
+		}
+	}
+
+	canEdit, err := s.CanEdit(userID, eventID)
+	if err != nil {
+		return Participants{}, err
+	}
+	if canEdit {
+		participants.Responses = responses
+		participants.Waitlist = waitlist
+	}
+
+	return participants, nil
+}
+
+// UpdateSearchIndex полностью перестраивает поисковый индекс. Изменения,
+// сделанные во время перестроения, после него повторно проходит инкрементальная индексация.
 func (s *EventService) UpdateSearchIndex() error {
+	startedAt := time.Now()
 	events, err := s.eventRepo.GetAll()
 
 	if err != nil {
 		return err
 	}
 
+	// удаленные и неопубликованные мероприятия в полный индекс не попадают
+	active := make([]repository.EventModel, 0, len(events))
 	for _, event := range events {
-		go createEventDocInElastic(&event)
+		if domain.IsPublished(event.Status) {
+			active = append(active, event)
+		}
 	}
 
-	return nil
+	if err := s.searcher.Reindex(active); err != nil {
+		return err
+	}
+
+	return s.checkpointRepo.Save(repository.SearchCheckpointModel{
+		Name:      indexCheckpointName,
+		UpdatedAt: startedAt.Add(-indexLag),
+	})
 }
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxStatusReasonLength = 1000
+
+// publishStatus - статус, в который мероприятие переходит при публикации от
+// имени userID: на одобрение, по расписанию или сразу
+func (s *EventService) publishStatus(orgID, userID uint, publishAt *time.Time) (string, error) {
+	requiresApproval, err := s.orgRepo.RequiresApproval(orgID)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := s.orgRepo.GetRole(orgID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	actor := policy.Actor{UserID: userID, OrgRole: role}
+	if requiresApproval && policy.CanApproveEvent(actor, policy.Event{}) != nil {
+		return domain.EventStatusPendingApproval, nil
+	}
+
+	return scheduledStatus(publishAt), nil
+}
+
+func scheduledStatus(publishAt *time.Time) string {
+	if publishAt != nil && publishAt.After(time.Now()) {
+		return domain.EventStatusScheduled
+	}
+
+	return domain.EventStatusActive
+}
+
+// Publish публикует черновик или меняет время отложенной публикации.
+// Возвращает новый статус.
+func (s *EventService) Publish(userID, eventID uint, input domain.PublishInput) (string, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return "", err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return "", err
+	}
+
+	if input.PublishAt != nil && !input.PublishAt.Before(event.StartsAt) {
+		var validation domain.ValidationError
+		validation.Add("publish_at", "Публикация должна быть раньше начала мероприятия")
+		return "", validation.Err()
+	}
+
+	status, err := s.publishStatus(event.OrganizationId, userID, input.PublishAt)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.eventRepo.TransitionStatus(eventID, repository.StatusChange{
+		To:        status,
+		ChangedBy: &userID,
+		Changes:   map[string]interface{}{"publish_at": input.PublishAt},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// Unpublish возвращает в черновики мероприятие, ожидающее одобрения или публикации
+func (s *EventService) Unpublish(userID, eventID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	_, err := s.eventRepo.TransitionStatus(eventID, repository.StatusChange{
+		To:        domain.EventStatusDraft,
+		ChangedBy: &userID,
+		Changes:   map[string]interface{}{"publish_at": nil},
+	})
+	return err
+}
+
+// Approve публикует мероприятие сразу или в запрошенное время
+func (s *EventService) Approve(userID, eventID uint) (string, error) {
+	if err := s.authorize(userID, eventID, policy.CanApproveEvent); err != nil {
+		return "", err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return "", err
+	}
+	if event.Status != domain.EventStatusPendingApproval {
+		return "", errors.New("invalid status transition")
+	}
+
+	status := scheduledStatus(event.PublishAt)
+	if _, err := s.eventRepo.ReviewEvent(eventID, repository.StatusChange{To: status, ChangedBy: &userID}, "event_approved"); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// Reject возвращает мероприятие создателю в черновики с причиной отказа
+func (s *EventService) Reject(userID, eventID uint, input domain.StatusReasonInput) error {
+	reason, err := validateStatusReason(input.Reason, false)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanApproveEvent); err != nil {
+		return err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event.Status != domain.EventStatusPendingApproval {
+		return errors.New("invalid status transition")
+	}
+
+	_, err = s.eventRepo.ReviewEvent(eventID, repository.StatusChange{To: domain.EventStatusDraft, Reason: reason, ChangedBy: &userID}, "event_rejected")
+	return err
+}
+
+// Cancel отменяет мероприятие: в отличие от удаления, оно остается видимым вместе с причиной
+func (s *EventService) Cancel(userID, eventID uint, input domain.StatusReasonInput) error {
+	reason, err := validateStatusReason(input.Reason, true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	_, err = s.eventRepo.TransitionStatus(eventID, repository.StatusChange{To: domain.EventStatusCancelled, Reason: reason, ChangedBy: &userID})
+	return err
+}
+
+// Postpone откладывает мероприятие до выбора новой даты; записи участников сохраняются
+func (s *EventService) Postpone(userID, eventID uint, input domain.StatusReasonInput) error {
+	reason, err := validateStatusReason(input.Reason, false)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	_, err = s.eventRepo.TransitionStatus(eventID, repository.StatusChange{To: domain.EventStatusPostponed, Reason: reason, ChangedBy: &userID})
+	return err
+}
+
+// Resume возвращает отложенное мероприятие в расписание; новую дату нужно
+// задать обычным редактированием до этого
+func (s *EventService) Resume(userID, eventID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event.Status == domain.EventStatusPostponed && !event.StartsAt.After(time.Now()) {
+		return errors.New("event date not set")
+	}
+
+	_, err = s.eventRepo.TransitionStatus(eventID, repository.StatusChange{To: domain.EventStatusActive, ChangedBy: &userID})
+	return err
+}
+
+// History - правки полей мероприятия для организаторов и соорганизаторов
+func (s *EventService) History(userID, eventID uint) ([]repository.EventRevisionModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetRevisions(eventID)
+}
+
+// StatusHistory - журнал смены статусов для организаторов
+func (s *EventService) StatusHistory(userID, eventID uint) ([]repository.EventStatusChangeModel, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetStatusChanges(eventID)
+}
+
+func validateStatusReason(reason string, required bool) (string, error) {
+	var validation domain.ValidationError
+
+	reason = strings.TrimSpace(reason)
+	if required && reason == "" {
+		validation.Add("reason", "Укажите причину")
+	} else if utf8.RuneCountInString(reason) > maxStatusReasonLength {
+		validation.Add("reason", fmt.Sprintf("Причина не должна быть длиннее %d символов", maxStatusReasonLength))
+	}
+
+	return reason, validation.Err()
+}
+
+func (s *EventService) StartPublisher() {
+	ticker := time.NewTicker(time.Minute)
+
+	go func() {
+		for range ticker.C {
+			if err := s.publishScheduled(); err != nil {
+				log.Println("Ошибка при публикации запланированных мероприятий:", err)
+			}
+		}
+	}()
+}
+
+// publishScheduled публикует мероприятия, время публикации которых наступило
+func (s *EventService) publishScheduled() error {
+	events, err := s.eventRepo.GetEventsToPublish()
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if _, err := s.eventRepo.TransitionStatus(event.ID, repository.StatusChange{To: domain.EventStatusActive}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
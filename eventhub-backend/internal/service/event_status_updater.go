@@ -1,25 +1,66 @@
 package service
 
 import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
 	"log"
 	"time"
 )
 
+// StartEventStatusUpdater переводит мероприятия в ongoing после начала и в
+// completed после окончания
 func (s *NotificationService) StartEventStatusUpdater() {
 	ticker := time.NewTicker(1 * time.Minute)
 
 	go func() {
 		for range ticker.C {
-			eventsIDsToMarkCompleted, err := s.eventRepo.GetEventIDsToMarkCompleted()
-			if err != nil {
-				log.Println("Ошибка при получении мероприятий для обновления статуса")
-			}
+			s.advanceEvents()
+		}
+	}()
+}
 
-			err = s.eventRepo.MarkEventsCompleted(eventsIDsToMarkCompleted)
-			if err != nil {
-				log.Println("Ошибка при обновлении статусов мероприятий:", err)
-			}
+func (s *NotificationService) advanceEvents() {
+	started, err := s.eventRepo.GetEventsToStart()
+	if err != nil {
+		log.Println("Ошибка при получении начавшихся мероприятий:", err)
+	}
+	for _, event := range started {
+		if _, err := s.eventRepo.TransitionStatus(event.ID, repository.StatusChange{To: domain.EventStatusOngoing}); err != nil {
+			log.Printf("Ошибка при обновлении статуса мероприятия %d: %v", event.ID, err)
+		}
+	}
 
+	finished, err := s.eventRepo.GetEventsToComplete()
+	if err != nil {
+		log.Println("Ошибка при получении мероприятий для обновления статуса")
+		return
+	}
+
+	completed := make([]repository.EventModel, 0, len(finished))
+	for _, event := range finished {
+		if _, err := s.eventRepo.TransitionStatus(event.ID, repository.StatusChange{To: domain.EventStatusCompleted}); err != nil {
+			log.Printf("Ошибка при обновлении статуса мероприятия %d: %v", event.ID, err)
+			continue
 		}
-	}()
+		completed = append(completed, event)
+	}
+
+	s.requestFeedback(completed)
+}
+
+// requestFeedback просит участников завершившихся мероприятий оставить отзыв
+func (s *NotificationService) requestFeedback(events []repository.EventModel) {
+	for _, event := range events {
+		participants, err := s.eventRepo.GetParticipantIDsByStatus(event.ID, []string{domain.RSVPGoing})
+		if err != nil {
+			log.Printf("Ошибка при получении участников для события %d: %v", event.ID, err)
+			continue
+		}
+
+		for _, userID := range participants {
+			if err := s.notificationRepo.Create(userID, event.ID, "feedback_request"); err != nil {
+				log.Printf("Ошибка при создании запроса отзыва: %v", err)
+			}
+		}
+	}
 }
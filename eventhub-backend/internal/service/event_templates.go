@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxTemplateNameLength = 100
+
+// Duplicate создает копию мероприятия с новыми датами в той же организации.
+// Копия получает анкету, типы билетов и файлы исходного, но не участников.
+func (s *EventService) Duplicate(userID, eventID uint, input domain.DuplicateEventInput) (repository.EventResponse, error) {
+	if err := s.authorize(userID, eventID, policy.CanDuplicateEvent); err != nil {
+		return repository.EventResponse{}, err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil || event.Status == domain.EventStatusDeleted {
+		return repository.EventResponse{}, errors.New("event not found")
+	}
+
+	create := domain.CreateEventInput{
+		Title:       event.Title,
+		Description: event.Description,
+		Category:    event.Category,
+		Location:    event.Location,
+		IsPublic:    &event.IsPublic,
+		StartsAt:    input.StartsAt,
+		Timezone:    event.Timezone,
+		Capacity:    event.Capacity,
+		Draft:       input.Draft,
+		PublishAt:   input.PublishAt,
+	}
+	if input.Title != "" {
+		create.Title = input.Title
+	}
+	if input.EndsAt != nil {
+		create.EndsAt = *input.EndsAt
+	} else if !input.StartsAt.IsZero() {
+		create.EndsAt = input.StartsAt.Add(event.EndsAt.Sub(event.StartsAt))
+	}
+
+	if err := validateEventInput(&create, true); err != nil {
+		return repository.EventResponse{}, err
+	}
+
+	extras, files, err := s.eventExtras(eventID)
+	if err != nil {
+		return repository.EventResponse{}, err
+	}
+
+	// окна продаж сдвигаются вместе с мероприятием
+	shift := create.StartsAt.Sub(event.StartsAt)
+	for i := range extras.TicketTypes {
+		extras.TicketTypes[i].SalesStart = shiftTime(extras.TicketTypes[i].SalesStart, shift)
+		extras.TicketTypes[i].SalesEnd = shiftTime(extras.TicketTypes[i].SalesEnd, shift)
+	}
+
+	created, err := s.createCopy(create, userID, event.OrganizationId, extras, files)
+	if err != nil {
+		return repository.EventResponse{}, err
+	}
+
+	response, _, err := s.eventRepo.GetByID(created.ID, userID)
+	return response, err
+}
+
+// Templates - права проверяет middleware маршрута
+func (s *EventService) Templates(orgID uint) ([]repository.EventTemplateModel, error) {
+	return s.eventRepo.GetTemplates(orgID)
+}
+
+// SaveTemplate сохраняет мероприятие организации как шаблон. Файлы копируются,
+// чтобы шаблон пережил удаление исходного мероприятия.
+func (s *EventService) SaveTemplate(userID, orgID uint, input domain.TemplateInput) (repository.EventTemplateModel, error) {
+	var validation domain.ValidationError
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		validation.Add("name", "Укажите название шаблона")
+	} else if utf8.RuneCountInString(input.Name) > maxTemplateNameLength {
+		validation.Add("name", fmt.Sprintf("Название не должно быть длиннее %d символов", maxTemplateNameLength))
+	}
+	if input.EventID == 0 {
+		validation.Add("event_id", "Укажите мероприятие")
+	}
+	if err := validation.Err(); err != nil {
+		return repository.EventTemplateModel{}, err
+	}
+
+	if err := s.authorize(userID, input.EventID, policy.CanDuplicateEvent); err != nil {
+		return repository.EventTemplateModel{}, err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(input.EventID)
+	if err != nil || event.Status == domain.EventStatusDeleted || event.OrganizationId != orgID {
+		return repository.EventTemplateModel{}, errors.New("event not found")
+	}
+
+	extras, files, err := s.eventExtras(event.ID)
+	if err != nil {
+		return repository.EventTemplateModel{}, err
+	}
+
+	template := repository.EventTemplateModel{
+		OrganizationID: orgID,
+		Name:           input.Name,
+		Title:          event.Title,
+		Description:    event.Description,
+		Category:       event.Category,
+		Location:       event.Location,
+		IsPublic:       event.IsPublic,
+		Timezone:       event.Timezone,
+		Capacity:       event.Capacity,
+		Duration:       int64(event.EndsAt.Sub(event.StartsAt) / time.Second),
+		FormFields:     []domain.FormFieldInput{},
+		TicketTypes:    []domain.TicketTypeInput{},
+		Attachments:    []repository.TemplateAttachment{},
+		CreatedBy:      userID,
+	}
+	for _, field := range extras.FormFields {
+		template.FormFields = append(template.FormFields, domain.FormFieldInput{
+			Label:     field.Label,
+			Kind:      field.Kind,
+			Required:  field.Required,
+			Options:   field.Options,
+			MaxLength: field.MaxLength,
+			Min:       field.Min,
+			Max:       field.Max,
+		})
+	}
+	for _, ticketType := range extras.TicketTypes {
+		template.TicketTypes = append(template.TicketTypes, domain.TicketTypeInput{Name: ticketType.Name, Quota: ticketType.Quota})
+	}
+
+	copies, err := s.copyFiles(files, fmt.Sprintf("templates/%d", orgID), userID)
+	if err != nil {
+		return repository.EventTemplateModel{}, err
+	}
+	for _, attachment := range copies {
+		template.Attachments = append(template.Attachments, repository.TemplateAttachment{
+			Kind:        attachment.Kind,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Key:         attachment.Key,
+			URL:         attachment.URL,
+			ThumbKey:    attachment.ThumbKey,
+			ThumbURL:    attachment.ThumbURL,
+		})
+	}
+
+	if err := s.eventRepo.CreateTemplate(&template); err != nil {
+		s.deleteBlobs(copies)
+		return repository.EventTemplateModel{}, err
+	}
+
+	return template, nil
+}
+
+func (s *EventService) DeleteTemplate(orgID, templateID uint) error {
+	template, err := s.eventRepo.GetTemplate(orgID, templateID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.eventRepo.DeleteTemplate(template.ID); err != nil {
+		return err
+	}
+	s.deleteBlobs(templateFiles(template))
+
+	return nil
+}
+
+// applyTemplate заполняет незаданные поля из шаблона. Закрытый шаблон не
+// мешает создать публичное мероприятие: is_public из запроса важнее.
+func applyTemplate(input *domain.CreateEventInput, template repository.EventTemplateModel) (repository.EventCopy, []repository.EventAttachmentModel) {
+	if input.Title == "" {
+		input.Title = template.Title
+	}
+	if input.Description == "" {
+		input.Description = template.Description
+	}
+	if input.Category == "" {
+		input.Category = template.Category
+	}
+	if input.Location == "" {
+		input.Location = template.Location
+	}
+	if input.Timezone == "" {
+		input.Timezone = template.Timezone
+	}
+	if input.Capacity == nil {
+		input.Capacity = template.Capacity
+	}
+	if input.IsPublic == nil {
+		input.IsPublic = &template.IsPublic
+	}
+	if input.EndsAt.IsZero() && !input.StartsAt.IsZero() && template.Duration > 0 {
+		input.EndsAt = input.StartsAt.Add(time.Duration(template.Duration) * time.Second)
+	}
+
+	var extras repository.EventCopy
+	for _, field := range template.FormFields {
+		extras.FormFields = append(extras.FormFields, repository.EventFormFieldModel{
+			Label:     field.Label,
+			Kind:      field.Kind,
+			Required:  field.Required,
+			Options:   field.Options,
+			MaxLength: field.MaxLength,
+			Min:       field.Min,
+			Max:       field.Max,
+		})
+	}
+	for _, ticketType := range template.TicketTypes {
+		extras.TicketTypes = append(extras.TicketTypes, repository.EventTicketTypeModel{Name: ticketType.Name, Quota: ticketType.Quota})
+	}
+
+	return extras, templateFiles(template)
+}
+
+func templateFiles(template repository.EventTemplateModel) []repository.EventAttachmentModel {
+	files := make([]repository.EventAttachmentModel, 0, len(template.Attachments))
+	for _, attachment := range template.Attachments {
+		files = append(files, repository.EventAttachmentModel{
+			Kind:        attachment.Kind,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Key:         attachment.Key,
+			URL:         attachment.URL,
+			ThumbKey:    attachment.ThumbKey,
+			ThumbURL:    attachment.ThumbURL,
+		})
+	}
+
+	return files
+}
+
+// eventExtras собирает анкету, типы билетов и файлы мероприятия для копии
+func (s *EventService) eventExtras(eventID uint) (repository.EventCopy, []repository.EventAttachmentModel, error) {
+	fields, err := s.eventRepo.GetFormFields(eventID)
+	if err != nil {
+		return repository.EventCopy{}, nil, err
+	}
+
+	ticketTypes, err := s.eventRepo.GetTicketTypeModels(eventID)
+	if err != nil {
+		return repository.EventCopy{}, nil, err
+	}
+
+	files, err := s.eventRepo.GetEventFiles(eventID)
+	if err != nil {
+		return repository.EventCopy{}, nil, err
+	}
+
+	return repository.EventCopy{FormFields: fields, TicketTypes: ticketTypes}, files, nil
+}
+
+// createCopy создает мероприятие с перенесенными анкетой, билетами и файлами.
+// Файлы копируются после создания, под ключами нового мероприятия; если
+// скопировать их не удалось, мероприятие остается без них, а ошибка логируется.
+func (s *EventService) createCopy(input domain.CreateEventInput, creatorID, orgID uint, extras repository.EventCopy, files []repository.EventAttachmentModel) (*repository.EventModel, error) {
+	status, err := s.createStatus(input, orgID, creatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.eventRepo.CreateCopy(input, creatorID, orgID, status, extras)
+	if err != nil {
+		return nil, err
+	}
+
+	copies, err := s.copyFiles(files, fmt.Sprintf("events/%d", event.ID), creatorID)
+	if err != nil {
+		log.Println("Ошибка при копировании файлов мероприятия", event.ID, err)
+		return event, nil
+	}
+	if err := s.eventRepo.AddFiles(event.ID, copies); err != nil {
+		s.deleteBlobs(copies)
+		log.Println("Ошибка при сохранении файлов мероприятия", event.ID, err)
+	}
+
+	return event, nil
+}
+
+// copyFiles копирует файлы и их уменьшенные копии под новыми ключами с префиксом prefix
+func (s *EventService) copyFiles(sources []repository.EventAttachmentModel, prefix string, userID uint) ([]repository.EventAttachmentModel, error) {
+	ctx := context.Background()
+	copies := make([]repository.EventAttachmentModel, 0, len(sources))
+
+	for _, source := range sources {
+		name := prefix + "/" + randomName()
+		attachment := repository.EventAttachmentModel{
+			Kind:        source.Kind,
+			Name:        source.Name,
+			ContentType: source.ContentType,
+			Size:        source.Size,
+			Key:         name + path.Ext(source.Key),
+			UploadedBy:  userID,
+		}
+		if err := s.blobStore.Copy(ctx, source.Key, attachment.Key); err != nil {
+			s.deleteBlobs(copies)
+			return nil, err
+		}
+		attachment.URL = s.blobStore.URL(attachment.Key)
+
+		if source.ThumbKey != "" {
+			attachment.ThumbKey = name + "_thumb.jpg"
+			if err := s.blobStore.Copy(ctx, source.ThumbKey, attachment.ThumbKey); err != nil {
+				s.deleteBlobs(append(copies, repository.EventAttachmentModel{Key: attachment.Key}))
+				return nil, err
+			}
+			attachment.ThumbURL = s.blobStore.URL(attachment.ThumbKey)
+		}
+
+		copies = append(copies, attachment)
+	}
+
+	return copies, nil
+}
+
+func shiftTime(t *time.Time, shift time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	shifted := t.Add(shift)
+	return &shifted
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
+	"eventhub-backend/internal/repository"
+	customJwt "eventhub-backend/pkg/jwt"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxTicketTypeNameLength = 100
+
+func validateTicketTypeInput(input *domain.TicketTypeInput) error {
+	var validation domain.ValidationError
+
+	input.Name = strings.TrimSpace(input.Name)
+	switch {
+	case input.Name == "":
+		validation.Add("name", "Название обязательно")
+	case utf8.RuneCountInString(input.Name) > maxTicketTypeNameLength:
+		validation.Add("name", fmt.Sprintf("Название не должно быть длиннее %d символов", maxTicketTypeNameLength))
+	}
+
+	if input.Quota != nil && *input.Quota < 1 {
+		validation.Add("quota", "Квота должна быть положительной")
+	}
+
+	if input.SalesStart != nil && input.SalesEnd != nil && !input.SalesEnd.After(*input.SalesStart) {
+		validation.Add("sales_end", "Окончание продаж должно быть позже начала")
+	}
+
+	return validation.Err()
+}
+
+func (s *EventService) GetTicketTypes(userID, eventID uint) ([]repository.TicketTypeAvailability, error) {
+	if err := s.authorize(userID, eventID, policy.CanViewEvent); err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.GetTicketTypes(eventID)
+}
+
+func (s *EventService) CreateTicketType(userID, eventID uint, input domain.TicketTypeInput) (repository.EventTicketTypeModel, error) {
+	if err := validateTicketTypeInput(&input); err != nil {
+		return repository.EventTicketTypeModel{}, err
+	}
+
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return repository.EventTicketTypeModel{}, err
+	}
+
+	ticketType := repository.EventTicketTypeModel{
+		EventID:    eventID,
+		Name:       input.Name,
+		Quota:      input.Quota,
+		SalesStart: input.SalesStart,
+		SalesEnd:   input.SalesEnd,
+	}
+	if err := s.eventRepo.CreateTicketType(&ticketType); err != nil {
+		return repository.EventTicketTypeModel{}, err
+	}
+
+	return ticketType, nil
+}
+
+func (s *EventService) DeleteTicketType(userID, eventID, ticketTypeID uint) error {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return err
+	}
+
+	return s.eventRepo.DeleteTicketType(eventID, ticketTypeID)
+}
+
+// checkTicketType проверяет выбор типа билета при записи: тип обязателен,
+// если у мероприятия они есть, и продажи должны быть открыты
+func (s *EventService) checkTicketType(eventID uint, ticketTypeID *uint) error {
+	if ticketTypeID == nil {
+		hasTypes, err := s.eventRepo.HasTicketTypes(eventID)
+		if err != nil {
+			return err
+		}
+		if hasTypes {
+			return errors.New("ticket type required")
+		}
+		return nil
+	}
+
+	ticketType, err := s.eventRepo.GetTicketType(eventID, *ticketTypeID)
+	if err != nil {
+		return errors.New("ticket type not found")
+	}
+
+	now := time.Now()
+	if (ticketType.SalesStart != nil && now.Before(*ticketType.SalesStart)) ||
+		(ticketType.SalesEnd != nil && !now.Before(*ticketType.SalesEnd)) {
+		return errors.New("ticket sales closed")
+	}
+
+	return nil
+}
+
+// ScannerSync - то, что сканер сверяет без сети: отозванные билеты, отмененные
+// мероприятия, все билеты которых недействительны, и текущий срок действия
+// билетов, который после переноса отличается от подписанного в них
+type ScannerSync struct {
+	TicketIDs []uint
+	EventIDs  []uint
+	ExpiresAt time.Time
+}
+
+// RevokedTickets - отозванные после since билеты для синхронизации сканеров
+func (s *EventService) RevokedTickets(userID, eventID uint, since time.Time) (ScannerSync, error) {
+	if err := s.authorize(userID, eventID, policy.CanEditEvent); err != nil {
+		return ScannerSync{}, err
+	}
+
+	event, err := s.eventRepo.GetEventModelByID(eventID)
+	if err != nil {
+		return ScannerSync{}, err
+	}
+
+	ticketIDs, err := s.eventRepo.GetRevokedTicketIDs(eventID, since)
+	if err != nil {
+		return ScannerSync{}, err
+	}
+
+	sync := ScannerSync{TicketIDs: ticketIDs, EventIDs: []uint{}, ExpiresAt: ticketExpiresAt(event)}
+	if ticketCancelled(event) {
+		sync.EventIDs = append(sync.EventIDs, eventID)
+	}
+
+	return sync, nil
+}
+
+// TicketKeys без ключа билетов - пустой набор
+func (s *EventService) TicketKeys() customJwt.JWKSet {
+	if s.ticketSigner == nil {
+		return customJwt.JWKSet{Keys: []customJwt.JWK{}}
+	}
+	return s.ticketSigner.Keys()
+}
@@ -1,19 +1,73 @@
 package service
 
 import (
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/repository"
 	"log"
 	"time"
 )
 
+const (
+	indexCheckpointName = "events"
+	indexBatchSize      = 500
+	// изменения моложе этого интервала откладываются до следующего прохода,
+	// чтобы не пропустить строки из транзакций, закоммиченных не по порядку
+	indexLag = 2 * time.Second
+)
+
 func (s *EventService) StartIndexUpdater() {
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(5 * time.Second)
 
 	go func() {
 		for range ticker.C {
-			err := s.UpdateSearchIndex()
+			err := s.syncSearchIndex()
 			if err != nil {
 				log.Println("Ошибка при обновлении индексов:", err)
 			}
 		}
 	}()
 }
+
+// syncSearchIndex отправляет в поисковый индекс мероприятия, измененные после
+// сохраненной отметки, и сдвигает отметку после каждой успешной пачки
+func (s *EventService) syncSearchIndex() error {
+	checkpoint, err := s.checkpointRepo.Get(indexCheckpointName)
+	if err != nil {
+		return err
+	}
+
+	for {
+		events, err := s.eventRepo.GetChangedSince(checkpoint.UpdatedAt, checkpoint.LastID, time.Now().Add(-indexLag), indexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		var toIndex []repository.EventModel
+		var toDelete []uint
+		for _, event := range events {
+			if !domain.IsPublished(event.Status) {
+				toDelete = append(toDelete, event.ID)
+			} else {
+				toIndex = append(toIndex, event)
+			}
+		}
+
+		if err := s.searcher.Bulk(toIndex, toDelete); err != nil {
+			return err
+		}
+
+		last := events[len(events)-1]
+		checkpoint.UpdatedAt = last.UpdatedAt
+		checkpoint.LastID = last.ID
+		if err := s.checkpointRepo.Save(checkpoint); err != nil {
+			return err
+		}
+
+		if len(events) < indexBatchSize {
+			return nil
+		}
+	}
+}
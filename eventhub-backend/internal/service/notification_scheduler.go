@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"eventhub-backend/internal/domain"
 	"log"
 	"time"
 
@@ -32,22 +33,14 @@ func (s *NotificationService) checkAndSendReminders() error {
 		return err
 	}
 
-	now := time.Now()
+	now := time.Now().UTC()
 
 	for _, event := range events {
-		status := event.Status
-		if status == "deleted" || status == "completed" {
+		if event.Status != domain.EventStatusActive {
 			continue
 		}
 
-		eventStartTime, err := time.Parse("15:04:05", event.StartTime)
-		if err != nil {
-			log.Printf("Ошибка при парсинге времени события %s: %v", event.StartTime, err)
-			continue
-		}
-
-		eventTime := combineDateTime(event.Date, eventStartTime)
-		duration := eventTime.Sub(now)
+		duration := event.StartsAt.Sub(now)
 
 		var reminderType string
 		switch {
@@ -59,7 +52,7 @@ func (s *NotificationService) checkAndSendReminders() error {
 			continue
 		}
 
-		participants, err := s.eventRepo.GetParticipantIDs(event.ID)
+		participants, err := s.eventRepo.GetParticipantIDsByStatus(event.ID, s.reminderStatuses())
 		if err != nil {
 			log.Printf("Ошибка при получении участников для события %d: %v", event.ID, err)
 			continue
@@ -73,6 +66,14 @@ func (s *NotificationService) checkAndSendReminders() error {
 	return nil
 }
 
+// reminderStatuses - ответы, при которых пользователь получает напоминания
+func (s *NotificationService) reminderStatuses() []string {
+	if s.remindMaybe {
+		return []string{domain.RSVPGoing, domain.RSVPMaybe}
+	}
+	return []string{domain.RSVPGoing}
+}
+
 func (s *NotificationService) trySendReminder(eventID, userID uint, reminderType string, now time.Time) {
 	alreadySent, err := s.notificationRepo.Exists(eventID, userID, reminderType)
 	if (err != nil && !errors.Is(err, gorm.ErrRecordNotFound)) || alreadySent {
@@ -85,10 +86,3 @@ func (s *NotificationService) trySendReminder(eventID, userID uint, reminderType
 		log.Printf("Ошибка при создании %s уведомления: %v", reminderType, err)
 	}
 }
-
-func combineDateTime(date time.Time, startTime time.Time) time.Time {
-	return time.Date(
-		date.Year(), date.Month(), date.Day(),
-		startTime.Hour(), startTime.Minute(), startTime.Second(), 0, date.Location(),
-	)
-}
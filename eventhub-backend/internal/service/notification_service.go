@@ -5,20 +5,23 @@ import (
 	"eventhub-backend/internal/domain"
 	"eventhub-backend/internal/repository"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type NotificationService struct {
 	notificationRepo repository.GormNotificationRepository
 	eventRepo        repository.GormEventRepository
+	// remindMaybe - отправлять напоминания и ответившим maybe
+	remindMaybe bool
 }
 
-func NewNotificationService(notificationRepo repository.GormNotificationRepository, eventRepo repository.GormEventRepository) *NotificationService {
-	return &NotificationService{notificationRepo: notificationRepo, eventRepo: eventRepo}
+func NewNotificationService(notificationRepo repository.GormNotificationRepository, eventRepo repository.GormEventRepository, remindMaybe bool) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo, eventRepo: eventRepo, remindMaybe: remindMaybe}
 }
 
 func (s *NotificationService) Create(userID, eventID uint, msgType string) error {
-	if msgType != "reminder_1d" && msgType != "reminder_1h" && msgType != "cancel" && msgType != "reschedule" {
+	if msgType != "reminder_1d" && msgType != "reminder_1h" && msgType != "cancel" && msgType != "reschedule" && msgType != "waitlist_promoted" && msgType != "postpone" {
 		return errors.New("incorrect msg type")
 	}
 
@@ -42,33 +45,13 @@ func (s *NotificationService) GetAll(userID uint) ([]domain.Notification, error)
 
 	var notificationsResponse []domain.Notification
 	for _, ntf := range notifications {
-		event, _, err := s.eventRepo.GetByID(ntf.EventID, ntf.UserID)
+		event, err := s.eventRepo.GetEventModelByID(ntf.EventID)
 		if err != nil {
 			continue
 		}
 
-		parsedDate, err := time.Parse("2006-01-02", event.Date)
-		if err != nil {
-			continue
-		}
-		parsedTime, err := time.Parse("15:04:05", event.StartTime)
-		if err != nil {
-			continue
-		}
-		eventDateTime := time.Date(
-			parsedDate.Year(), parsedDate.Month(), parsedDate.Day(),
-			parsedTime.Hour(), parsedTime.Minute(), 0, 0, time.Local,
-		)
-		months := []string{
-			"января", "февраля", "марта", "апреля", "мая", "июня",
-			"июля", "августа", "сентября", "октября", "ноября", "декабря",
-		}
-		formatted := fmt.Sprintf("%d %s, %02d:%02d",
-			eventDateTime.Day(),
-			months[eventDateTime.Month()-1],
-			eventDateTime.Hour(),
-			eventDateTime.Minute(),
-		)
+		// время показывается в часовом поясе мероприятия
+		formatted := formatDateTime(event.StartsAt.In(event.TimeLocation()))
 
 		var message, info string
 		switch ntf.Type {
@@ -81,9 +64,44 @@ func (s *NotificationService) GetAll(userID uint) ([]domain.Notification, error)
 		case "cancel":
 			message = fmt.Sprintf("😔 Мероприятие «%s» отменено", event.Title)
 			info = "К сожалению, мероприятие не состоится. Надеемся увидеть тебя на других событиях!"
+			if event.Status == domain.EventStatusCancelled && event.StatusReason != "" {
+				info = fmt.Sprintf("К сожалению, мероприятие не состоится. Причина: %s", event.StatusReason)
+			}
+		case "postpone":
+			message = fmt.Sprintf("⏸ Мероприятие «%s» отложено", event.Title)
+			info = "Новая дата пока не известна. Твоя запись сохранится, и мы сообщим, когда мероприятие вернется в расписание."
+			if event.StatusReason != "" {
+				info = fmt.Sprintf("Причина: %s. Твоя запись сохранится, и мы сообщим новую дату.", event.StatusReason)
+			}
 		case "reschedule":
 			message = fmt.Sprintf("❗ Мероприятие «%s» перенесено", event.Title)
 			info = fmt.Sprintf("Новое время: %s. Мы ждем тебя!", formatted)
+			if ntf.RevisionID != nil {
+				if revision, err := s.eventRepo.GetRevision(*ntf.RevisionID); err == nil {
+					message, info = rescheduleMessage(event, revision)
+				}
+			}
+		case "waitlist_promoted":
+			message = fmt.Sprintf("🎉 Для тебя освободилось место на «%s»", event.Title)
+			info = fmt.Sprintf("Ты больше не в очереди и записан на мероприятие. Ждем тебя %s!", formatted)
+		case "mention":
+			message = fmt.Sprintf("💬 Тебя упомянули в обсуждении «%s»", event.Title)
+			info = "Загляни в комментарии мероприятия, чтобы ответить."
+		case "event_approved":
+			message = fmt.Sprintf("✅ Мероприятие «%s» одобрено", event.Title)
+			info = "Администратор организации одобрил мероприятие, и оно опубликовано."
+			if event.Status == domain.EventStatusScheduled && event.PublishAt != nil {
+				info = "Администратор организации одобрил мероприятие. Оно будет опубликовано в назначенное время."
+			}
+		case "event_rejected":
+			message = fmt.Sprintf("✋ Мероприятие «%s» не одобрено", event.Title)
+			info = "Мероприятие вернулось в черновики."
+			if event.StatusReason != "" {
+				info = fmt.Sprintf("Мероприятие вернулось в черновики. Причина: %s", event.StatusReason)
+			}
+		case "feedback_request":
+			message = fmt.Sprintf("⭐ Как прошло «%s»?", event.Title)
+			info = "Поставь оценку и оставь отзыв, чтобы организаторы сделали следующие мероприятия лучше."
 		default:
 			continue
 		}
@@ -98,3 +116,65 @@ func (s *NotificationService) GetAll(userID uint) ([]domain.Notification, error)
 
 	return notificationsResponse, nil
 }
+
+var months = []string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+func formatDateTime(t time.Time) string {
+	return fmt.Sprintf("%d %s, %02d:%02d", t.Day(), months[t.Month()-1], t.Hour(), t.Minute())
+}
+
+// rescheduleMessage описывает правку по сохраненным в ней прежним и новым
+// значениям, а не по текущему состоянию мероприятия
+func rescheduleMessage(event repository.EventModel, revision repository.EventRevisionModel) (string, string) {
+	oldLoc, newLoc := event.TimeLocation(), event.TimeLocation()
+	for _, change := range revision.Changes {
+		if change.Field == "timezone" {
+			oldLoc = repository.LoadLocation(fmt.Sprint(change.Old))
+			newLoc = repository.LoadLocation(fmt.Sprint(change.New))
+		}
+	}
+
+	var lines []string
+	moved := false
+	for _, change := range revision.Changes {
+		switch change.Field {
+		case "starts_at":
+			moved = true
+			lines = append(lines, fmt.Sprintf("Начало: было %s, стало %s", formatChangeTime(change.Old, oldLoc), formatChangeTime(change.New, newLoc)))
+		case "ends_at":
+			moved = true
+			lines = append(lines, fmt.Sprintf("Окончание: было %s, стало %s", formatChangeTime(change.Old, oldLoc), formatChangeTime(change.New, newLoc)))
+		case "location":
+			lines = append(lines, fmt.Sprintf("Место: было %s, стало %s", formatLocation(change.Old), formatLocation(change.New)))
+		}
+	}
+
+	message := fmt.Sprintf("📍 У мероприятия «%s» новое место", event.Title)
+	if moved {
+		message = fmt.Sprintf("❗ Мероприятие «%s» перенесено", event.Title)
+	}
+
+	return message, strings.Join(lines, ". ") + "."
+}
+
+// formatChangeTime - время из правки приходит строкой RFC 3339
+func formatChangeTime(value interface{}, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339, fmt.Sprint(value))
+	if err != nil {
+		return "не указано"
+	}
+
+	return formatDateTime(t.In(loc))
+}
+
+func formatLocation(value interface{}) string {
+	location, _ := value.(string)
+	if location == "" {
+		return "не указано"
+	}
+
+	return "«" + location + "»"
+}
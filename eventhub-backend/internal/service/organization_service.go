@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"eventhub-backend/internal/domain"
+	"eventhub-backend/internal/policy"
 	"eventhub-backend/internal/repository"
 )
 
@@ -33,16 +35,65 @@ func (s *OrganizationService) JoinByCode(userID uint, code string) error {
 	return s.organizationRepo.JoinByCode(userID, code)
 }
 
-func (s *OrganizationService) GetEvents(orgID uint) ([]repository.EventResponse, []repository.EventResponse, error) {
+// GetEvents возвращает мероприятия по группам статусов; неопубликованные
+// видны только тем, кто может их редактировать
+func (s *OrganizationService) GetEvents(orgID, userID uint) (map[string][]repository.EventResponse, error) {
 	exists, err := s.organizationRepo.IsOrganizationExist(orgID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if !exists {
-		return nil, nil, errors.New("organization not exists")
+		return nil, errors.New("organization not exists")
+	}
+
+	actor, err := s.actor(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.organizationRepo.GetEvents(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	joinedIDs, hostedIDs, err := s.organizationRepo.GetUserEventIDs(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for group, events := range groups {
+		groups[group] = visibleEvents(actor, events, idSet(joinedIDs), idSet(hostedIDs))
+	}
+
+	return groups, nil
+}
+
+// visibleEvents проверяет каждое мероприятие так же, как EventService.actor:
+// с участием и правами соорганизатора, которые есть только у участников организации
+func visibleEvents(actor policy.Actor, events []repository.EventResponse, joined, hosted map[uint]bool) []repository.EventResponse {
+	visible := make([]repository.EventResponse, 0, len(events))
+	for _, event := range events {
+		actor.Joined = joined[event.ID]
+		actor.Host = hosted[event.ID] && actor.OrgRole != ""
+		if policy.CanViewEvent(actor, policy.Event{CreatorID: event.CreatorId, IsPublic: event.IsPublic, Status: event.Status}) == nil {
+			visible = append(visible, event)
+		}
 	}
 
-	return s.organizationRepo.GetEvents(orgID)
+	return visible
+}
+
+func idSet(ids []uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
+
+func (s *OrganizationService) UpdateSettings(orgID uint, settings domain.OrganizationSettingsInput) error {
+	return s.organizationRepo.UpdateSettings(orgID, settings)
 }
 
 func (s *OrganizationService) GetCreator(orgID uint) (uint, error) {
@@ -58,10 +109,18 @@ func (s *OrganizationService) GetByID(orgID, userID uint) (repository.Organizati
 		return repository.OrganizationModel{}, false, errors.New("organization not exists")
 	}
 
+	actor, err := s.actor(orgID, userID)
+	if err != nil {
+		return repository.OrganizationModel{}, false, err
+	}
+	if err := policy.CanViewOrganization(actor); err != nil {
+		return repository.OrganizationModel{}, false, err
+	}
+
 	return s.organizationRepo.GetByID(orgID, userID)
 }
 
-func (s *OrganizationService) GetMembers(orgID uint) ([]repository.UserAsMember, error) {
+func (s *OrganizationService) GetMembers(orgID, userID uint) ([]repository.UserAsMember, error) {
 	exists, err := s.organizationRepo.IsOrganizationExist(orgID)
 	if err != nil {
 		return nil, err
@@ -70,5 +129,84 @@ func (s *OrganizationService) GetMembers(orgID uint) ([]repository.UserAsMember,
 		return nil, errors.New("organization not exists")
 	}
 
+	actor, err := s.actor(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CanListMembers(actor); err != nil {
+		return nil, err
+	}
+
 	return s.organizationRepo.GetMembers(orgID)
 }
+
+func (s *OrganizationService) actor(orgID, userID uint) (policy.Actor, error) {
+	role, err := s.organizationRepo.GetRole(orgID, userID)
+	if err != nil {
+		return policy.Actor{}, err
+	}
+
+	return policy.Actor{UserID: userID, OrgRole: role}, nil
+}
+
+func (s *OrganizationService) GetRole(orgID, userID uint) (string, error) {
+	return s.organizationRepo.GetRole(orgID, userID)
+}
+
+func (s *OrganizationService) HasPermission(orgID, userID uint, perm domain.Permission) (bool, error) {
+	actor, err := s.actor(orgID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return policy.CanInOrganization(actor, perm) == nil, nil
+}
+
+func (s *OrganizationService) Promote(actorID, orgID, targetID uint) (string, error) {
+	return s.changeRole(actorID, orgID, targetID, 1)
+}
+
+func (s *OrganizationService) Demote(actorID, orgID, targetID uint) (string, error) {
+	return s.changeRole(actorID, orgID, targetID, -1)
+}
+
+// changeRole сдвигает участника по лестнице ролей. Назначать можно только роли
+// ниже своей и только тем, кто сейчас ниже по рангу.
+func (s *OrganizationService) changeRole(actorID, orgID, targetID uint, step int) (string, error) {
+	actor, err := s.actor(orgID, actorID)
+	if err != nil {
+		return "", err
+	}
+	if err := policy.CanInOrganization(actor, domain.PermManageMembers); err != nil {
+		return "", err
+	}
+
+	targetRole, err := s.organizationRepo.GetRole(orgID, targetID)
+	if err != nil {
+		return "", err
+	}
+	if targetRole == "" {
+		return "", errors.New("user is not a member")
+	}
+
+	actorRank := domain.RoleRank(actor.OrgRole)
+	targetRank := domain.RoleRank(targetRole)
+	if targetRank >= actorRank {
+		return "", errors.New("access denied")
+	}
+
+	newRank := targetRank + step
+	if newRank < 1 {
+		return "", errors.New("role limit reached")
+	}
+	if newRank >= actorRank {
+		return "", errors.New("access denied")
+	}
+
+	newRole := domain.RoleLadder[newRank-1]
+	if err := s.organizationRepo.SetRole(orgID, targetID, newRole); err != nil {
+		return "", err
+	}
+
+	return newRole, nil
+}
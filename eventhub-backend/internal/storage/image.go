@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ограничение защищает от картинок, которые при декодировании занимают гигабайты
+const maxImagePixels = 40_000_000
+
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+func IsImageType(contentType string) bool {
+	return imageTypes[contentType]
+}
+
+// Thumbnail уменьшает картинку до ширины width с сохранением пропорций и
+// кодирует в JPEG. Картинки меньше width не увеличиваются.
+func Thumbnail(data []byte, width int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, errors.New("image too large")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+
+	bounds := src.Bounds()
+	size := bounds.Size()
+	if size.X > width {
+		size = image.Pt(width, max(size.Y*width/size.X, 1))
+	}
+
+	// JPEG без прозрачности: прозрачные области становятся белыми
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 82}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
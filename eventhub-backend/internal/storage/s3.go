@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Store хранит файлы в S3-совместимом хранилище (AWS S3, MinIO)
+type S3Store struct {
+	client    *minio.Client
+	bucket    string
+	region    string
+	publicURL string
+	// publicRead - ссылки ведут прямо в бакет, поэтому он должен быть открыт на чтение
+	publicRead bool
+}
+
+func NewS3Store(options S3Options, publicURL string) (*S3Store, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure: options.UseSSL,
+		Region: options.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// без отдельного адреса файлы отдаются напрямую из бакета
+	publicRead := publicURL == ""
+	if publicRead {
+		scheme := "http"
+		if options.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, options.Endpoint, options.Bucket)
+	}
+
+	return &S3Store{
+		client:     client,
+		bucket:     options.Bucket,
+		region:     options.Region,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		publicRead: publicRead,
+	}, nil
+}
+
+// EnsureBucket создает бакет, если его еще нет. Если файлы отдаются прямо из
+// бакета, открывает его на анонимное чтение: иначе ссылки URL отвечают 403.
+func (s *S3Store) EnsureBucket(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region}); err != nil {
+			return err
+		}
+	}
+	if !s.publicRead {
+		return nil
+	}
+
+	return s.client.SetBucketPolicy(ctx, s.bucket, readOnlyPolicy(s.bucket))
+}
+
+// readOnlyPolicy разрешает всем только скачивание объектов, без листинга бакета
+func readOnlyPolicy(bucket string) string {
+	return fmt.Sprintf(`{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["*"]},
+		"Action": ["s3:GetObject"],
+		"Resource": ["arn:aws:s3:::%s/*"]
+	}]
+}`, bucket)
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) Copy(ctx context.Context, srcKey, dstKey string) error {
+	_, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: dstKey},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: srcKey})
+	return err
+}
+
+func (s *S3Store) URL(key string) string {
+	return s.publicURL + "/" + key
+}
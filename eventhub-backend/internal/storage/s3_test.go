@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Интеграционный тест S3Store. Нужен S3-совместимый сервер, например MinIO из
+// docker-compose.yml; без S3_ENDPOINT тест пропускается:
+//
+//	S3_ENDPOINT=localhost:9000 S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin go test ./internal/storage
+//
+// Тест создает собственный бакет и удаляет его в конце.
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT не задан")
+	}
+
+	options := S3Options{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		Bucket:    fmt.Sprintf("eventhub-test-%d", time.Now().UnixNano()),
+		Region:    os.Getenv("S3_REGION"),
+		UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+	}
+
+	// publicURL пуст: ссылки ведут прямо в бакет
+	store, err := NewS3Store(options, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := store.EnsureBucket(ctx); err != nil {
+		t.Skipf("S3 недоступен: %v", err)
+	}
+	t.Cleanup(func() { removeBucket(t, store) })
+
+	// повторный вызов при старте сервера не должен падать на существующем бакете
+	if err := store.EnsureBucket(ctx); err != nil {
+		t.Fatalf("повторный EnsureBucket: %v", err)
+	}
+
+	content := []byte("eventhub cover")
+	if err := store.Put(ctx, "events/1/cover.txt", bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("URL", func(t *testing.T) {
+		want := fmt.Sprintf("http://%s/%s/events/1/cover.txt", endpoint, options.Bucket)
+		if options.UseSSL {
+			want = "https" + want[len("http"):]
+		}
+		if got := store.URL("events/1/cover.txt"); got != want {
+			t.Fatalf("URL = %s, ожидался %s", got, want)
+		}
+
+		// без авторизации: так файл открывает клиент
+		expectContent(t, store.URL("events/1/cover.txt"), content, "text/plain")
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		if err := store.Copy(ctx, "events/1/cover.txt", "events/2/cover.txt"); err != nil {
+			t.Fatal(err)
+		}
+		expectContent(t, store.URL("events/2/cover.txt"), content, "text/plain")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.Delete(ctx, "events/2/cover.txt"); err != nil {
+			t.Fatal(err)
+		}
+		expectMissing(t, store, "events/2/cover.txt")
+		// оригинал не зависит от копии
+		expectContent(t, store.URL("events/1/cover.txt"), content, "text/plain")
+
+		// удаление отсутствующего файла не ошибка
+		if err := store.Delete(ctx, "events/2/cover.txt"); err != nil {
+			t.Fatalf("повторное удаление: %v", err)
+		}
+	})
+}
+
+func TestS3StoreCustomPublicURL(t *testing.T) {
+	store, err := NewS3Store(S3Options{Endpoint: "localhost:9000", Bucket: "eventhub"}, "https://cdn.example.com/files/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.URL("events/1/a.jpg"); got != "https://cdn.example.com/files/events/1/a.jpg" {
+		t.Fatalf("URL = %s", got)
+	}
+	// доступом к бакету за CDN управляют отдельно
+	if store.publicRead {
+		t.Fatal("бакет за собственным адресом не должен открываться на чтение")
+	}
+}
+
+func expectContent(t *testing.T, url string, want []byte, contentType string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: статус %d", url, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != contentType {
+		t.Errorf("GET %s: Content-Type %q, ожидался %q", url, got, contentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, want) {
+		t.Fatalf("GET %s: получено %q, ожидалось %q", url, body, want)
+	}
+}
+
+func expectMissing(t *testing.T, store *S3Store, key string) {
+	t.Helper()
+
+	_, err := store.client.StatObject(context.Background(), store.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		t.Fatalf("%s не удален", key)
+	}
+	if code := minio.ToErrorResponse(err).Code; code != "NoSuchKey" {
+		t.Fatalf("%s: ожидалась ошибка NoSuchKey, получено %v", key, err)
+	}
+}
+
+func removeBucket(t *testing.T, store *S3Store) {
+	ctx := context.Background()
+	for object := range store.client.ListObjects(ctx, store.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			t.Logf("список объектов: %v", object.Err)
+			return
+		}
+		if err := store.Delete(ctx, object.Key); err != nil {
+			t.Logf("удаление %s: %v", object.Key, err)
+		}
+	}
+	if err := store.client.RemoveBucket(ctx, store.bucket); err != nil {
+		t.Logf("удаление бакета: %v", err)
+	}
+}
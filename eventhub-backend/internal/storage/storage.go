@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+)
+
+// BlobStore хранит загруженные файлы; ключ - путь вида events/1/abc.jpg
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// Copy копирует файл под новым ключом, чтобы у копии была своя судьба
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	// URL возвращает публичную ссылку на файл
+	URL(key string) string
+}
+
+type S3Options struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// New выбирает хранилище по имени бэкенда из конфигурации. publicURL - адрес,
+// по которому файлы доступны клиентам; если он пуст, для локального хранилища
+// это /files на самом сервере, для S3 - адрес бакета, открытого на чтение.
+func New(backend, localDir, publicURL string, s3 S3Options) (BlobStore, error) {
+	switch backend {
+	case "local":
+		if publicURL == "" {
+			publicURL = "http://localhost:3000/files"
+		}
+		return NewLocalStore(localDir, publicURL), nil
+	case "s3":
+		store, err := NewS3Store(s3, publicURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.EnsureBucket(context.Background()); err != nil {
+			log.Println("Ошибка при подготовке бакета S3:", err)
+		}
+		return store, nil
+	}
+
+	return nil, fmt.Errorf("unknown storage backend %q", backend)
+}
@@ -0,0 +1,74 @@
+package customJwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func accessClaims(userID uint) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": userID,
+		"exp": time.Now().Add(AccessTokenTTL).Unix(),
+		"typ": "access",
+	}
+}
+
+func refreshClaims(userID uint, jti string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": userID,
+		"exp": time.Now().Add(RefreshTokenTTL).Unix(),
+		"typ": "refresh",
+		"jti": jti,
+	}
+}
+
+func accessSubject(claims jwt.MapClaims) (uint, error) {
+	// refresh-токен не должен работать как access-токен
+	if typ, _ := claims["typ"].(string); typ == "refresh" {
+		return 0, errors.New("invalid token type")
+	}
+
+	return subject(claims)
+}
+
+func refreshSubject(claims jwt.MapClaims) (uint, string, error) {
+	if typ, _ := claims["typ"].(string); typ != "refresh" {
+		return 0, "", errors.New("invalid token type")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return 0, "", errors.New("invalid jti")
+	}
+
+	userID, err := subject(claims)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return userID, jti, nil
+}
+
+func subject(claims jwt.MapClaims) (uint, error) {
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, errors.New("invalid userID")
+	}
+
+	return uint(sub), nil
+}
+
+func mapClaims(token *jwt.Token, err error) (jwt.MapClaims, error) {
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid claims")
+	}
+
+	return claims, nil
+}
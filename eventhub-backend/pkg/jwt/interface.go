@@ -2,6 +2,8 @@ package customJwt
 
 type Manager interface {
 	GenerateAccessToken(userID uint) (string, error)
-	GenerateRefreshToken(userID uint) (string, error)
+	GenerateRefreshToken(userID uint, jti string) (string, error)
 	ParseToken(tokenStr string) (uint, error)
+	ParseRefreshToken(tokenStr string) (uint, string, error)
+	JWKS() JWKSet
 }
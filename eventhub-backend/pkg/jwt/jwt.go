@@ -5,7 +5,11 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt"
-	"github.com/google/uuid"
+)
+
+const (
+	AccessTokenTTL  = time.Minute * 3
+	RefreshTokenTTL = time.Hour * 24
 )
 
 type JwtManager struct {
@@ -17,55 +21,43 @@ func NewJwtManager(secretKey string) *JwtManager {
 }
 
 func (jm *JwtManager) GenerateAccessToken(userID uint) (string, error) {
-	// key := []byte(config.Load().JwtSecretKey)
-
-	claims := &jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(time.Minute * 3).Unix(),
-	}
-
-	// refreshClaims := &jwt.MapClaims{
-	// 	"sub": userId,
-	// 	"exp": time.Now().Add(time.Hour * 24).Unix(),
-	// 	"typ": "refresh",
-	// 	"jti": uuid.NewString(),
-	// }
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// refreshTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims(userID))
 	return token.SignedString([]byte(jm.secretKey))
 }
 
-func (jm *JwtManager) GenerateRefreshToken(userID uint) (string, error) {
-	claims := &jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-		"typ": "refresh",
-		"jti": uuid.NewString(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+func (jm *JwtManager) GenerateRefreshToken(userID uint, jti string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims(userID, jti))
 	return token.SignedString([]byte(jm.secretKey))
 }
 
 func (jm *JwtManager) ParseToken(tokenStr string) (uint, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jm.secretKey), nil
-	})
-	if err != nil || !token.Valid {
-		return 0, errors.New("invalid or expired token")
+	claims, err := jm.parse(tokenStr)
+	if err != nil {
+		return 0, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("invalid claims")
-	}
+	return accessSubject(claims)
+}
 
-	sub, ok := claims["sub"].(float64)
-	if !ok {
-		return 0, errors.New("invalid userID")
+func (jm *JwtManager) ParseRefreshToken(tokenStr string) (uint, string, error) {
+	claims, err := jm.parse(tokenStr)
+	if err != nil {
+		return 0, "", err
 	}
 
-	return uint(sub), nil
+	return refreshSubject(claims)
+}
+
+// JWKS для симметричного ключа пуст: секрет нельзя публиковать
+func (jm *JwtManager) JWKS() JWKSet {
+	return JWKSet{Keys: []JWK{}}
+}
+
+func (jm *JwtManager) parse(tokenStr string) (jwt.MapClaims, error) {
+	return mapClaims(jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(jm.secretKey), nil
+	}))
 }
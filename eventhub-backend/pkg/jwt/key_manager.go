@@ -0,0 +1,181 @@
+package customJwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// KeyManager подписывает токены асимметричным ключом (RS256 или EdDSA).
+// Помимо текущего ключа подписи проверяет токены старыми ключами,
+// чтобы ротация не разлогинивала пользователей.
+type KeyManager struct {
+	method     jwt.SigningMethod
+	signingKID string
+	signingKey crypto.PrivateKey
+	verifyKeys map[string]crypto.PublicKey
+}
+
+func NewKeyManager(alg, signingKID string, signingKey crypto.Signer, verifyKeys map[string]crypto.PublicKey) (*KeyManager, error) {
+	method, err := signingMethod(alg)
+	if err != nil {
+		return nil, err
+	}
+	if signingKID == "" {
+		return nil, errors.New("signing key id is empty")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(verifyKeys)+1)
+	for kid, key := range verifyKeys {
+		keys[kid] = key
+	}
+	keys[signingKID] = signingKey.Public()
+
+	return &KeyManager{
+		method:     method,
+		signingKID: signingKID,
+		signingKey: signingKey,
+		verifyKeys: keys,
+	}, nil
+}
+
+// LoadKeyManager читает PEM-ключи с диска: приватный ключ подписи и
+// публичные ключи, которые еще должны приниматься при проверке.
+func LoadKeyManager(alg, signingKID, privateKeyPath string, verifyKeyPaths map[string]string) (*KeyManager, error) {
+	pemBytes, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := parsePrivateKey(alg, pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", signingKID, err)
+	}
+
+	verifyKeys := make(map[string]crypto.PublicKey, len(verifyKeyPaths))
+	for kid, path := range verifyKeyPaths {
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parsePublicKey(alg, pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("verify key %s: %w", kid, err)
+		}
+		verifyKeys[kid] = key
+	}
+
+	return NewKeyManager(alg, signingKID, signingKey, verifyKeys)
+}
+
+func (km *KeyManager) GenerateAccessToken(userID uint) (string, error) {
+	return km.sign(accessClaims(userID))
+}
+
+func (km *KeyManager) GenerateRefreshToken(userID uint, jti string) (string, error) {
+	return km.sign(refreshClaims(userID, jti))
+}
+
+func (km *KeyManager) ParseToken(tokenStr string) (uint, error) {
+	claims, err := km.parse(tokenStr)
+	if err != nil {
+		return 0, err
+	}
+
+	return accessSubject(claims)
+}
+
+func (km *KeyManager) ParseRefreshToken(tokenStr string) (uint, string, error) {
+	claims, err := km.parse(tokenStr)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return refreshSubject(claims)
+}
+
+func (km *KeyManager) JWKS() JWKSet {
+	kids := make([]string, 0, len(km.verifyKeys))
+	for kid := range km.verifyKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKSet{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		if jwk, ok := publicJWK(kid, km.method.Alg(), km.verifyKeys[kid]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+func (km *KeyManager) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(km.method, claims)
+	token.Header["kid"] = km.signingKID
+	return token.SignedString(km.signingKey)
+}
+
+func (km *KeyManager) parse(tokenStr string) (jwt.MapClaims, error) {
+	return mapClaims(jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != km.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := km.verifyKeys[kid]
+		if !ok {
+			return nil, errors.New("unknown key id")
+		}
+
+		return key, nil
+	}))
+}
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+}
+
+func parsePrivateKey(alg string, pemBytes []byte) (crypto.Signer, error) {
+	switch alg {
+	case "RS256":
+		return jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	case "EdDSA":
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		return key.(ed25519.PrivateKey), nil
+	}
+
+	return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+}
+
+func parsePublicKey(alg string, pemBytes []byte) (crypto.PublicKey, error) {
+	switch alg {
+	case "RS256":
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	case "EdDSA":
+		return jwt.ParseEdPublicKeyFromPEM(pemBytes)
+	}
+
+	return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+}
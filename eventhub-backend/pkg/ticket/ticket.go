@@ -0,0 +1,192 @@
+package ticket
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	customJwt "eventhub-backend/pkg/jwt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// версия формата - первый байт полезной нагрузки
+const formatVersion = 1
+
+// Ticket - содержимое билета. Сканер проверяет его без обращения к API:
+// подпись публичным ключом, событие и срок действия.
+type Ticket struct {
+	ID        uint
+	EventID   uint
+	UserID    uint
+	TypeID    uint // 0 - мероприятие без типов билетов
+	ExpiresAt time.Time
+}
+
+// Signer подписывает билеты Ed25519. Билет - base64url от полезной
+// нагрузки и подписи, без заголовков, чтобы QR-код оставался маленьким.
+type Signer struct {
+	signingKID string
+	signingKey ed25519.PrivateKey
+	verifyKeys map[string]ed25519.PublicKey
+}
+
+func NewSigner(signingKID string, signingKey ed25519.PrivateKey, verifyKeys map[string]ed25519.PublicKey) (*Signer, error) {
+	if signingKID == "" {
+		return nil, errors.New("signing key id is empty")
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(verifyKeys)+1)
+	for kid, key := range verifyKeys {
+		keys[kid] = key
+	}
+	keys[signingKID] = signingKey.Public().(ed25519.PublicKey)
+
+	return &Signer{signingKID: signingKID, signingKey: signingKey, verifyKeys: keys}, nil
+}
+
+// LoadSigner читает PEM-ключи с диска так же, как ключи JWT
+func LoadSigner(signingKID, privateKeyPath string, verifyKeyPaths map[string]string) (*Signer, error) {
+	pemBytes, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("ticket key is not ed25519")
+	}
+
+	verifyKeys := make(map[string]ed25519.PublicKey, len(verifyKeyPaths))
+	for kid, path := range verifyKeyPaths {
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("ticket key is not ed25519")
+		}
+		verifyKeys[kid] = publicKey
+	}
+
+	return NewSigner(signingKID, signingKey, verifyKeys)
+}
+
+func (s *Signer) Sign(t Ticket) string {
+	payload := encode(t)
+	signed := append(payload, ed25519.Sign(s.signingKey, payload)...)
+	return base64.RawURLEncoding.EncodeToString(signed)
+}
+
+// Verify проверяет подпись любым из известных ключей и срок действия
+func (s *Signer) Verify(token string) (Ticket, error) {
+	t, err := s.VerifySignature(token)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if time.Now().After(t.ExpiresAt) {
+		return Ticket{}, errors.New("ticket expired")
+	}
+
+	return t, nil
+}
+
+// VerifySignature проверяет только подпись. ExpiresAt фиксируется при выдаче,
+// поэтому тот, кто знает текущее расписание мероприятия, сверяет срок с ним.
+func (s *Signer) VerifySignature(token string) (Ticket, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) <= ed25519.SignatureSize {
+		return Ticket{}, errors.New("malformed ticket")
+	}
+
+	payload, signature := raw[:len(raw)-ed25519.SignatureSize], raw[len(raw)-ed25519.SignatureSize:]
+
+	verified := false
+	for _, key := range s.verifyKeys {
+		if ed25519.Verify(key, payload, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Ticket{}, errors.New("invalid signature")
+	}
+
+	return decode(payload)
+}
+
+// Keys - публичные ключи для сканеров в формате JWKS
+func (s *Signer) Keys() customJwt.JWKSet {
+	kids := make([]string, 0, len(s.verifyKeys))
+	for kid := range s.verifyKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := customJwt.JWKSet{Keys: make([]customJwt.JWK, 0, len(kids))}
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, customJwt.JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: "EdDSA",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(s.verifyKeys[kid]),
+		})
+	}
+
+	return set
+}
+
+// encode: версия, затем uvarint-поля ID, EventID, UserID, TypeID и
+// срок действия в секундах Unix
+func encode(t Ticket) []byte {
+	buf := []byte{formatVersion}
+	buf = binary.AppendUvarint(buf, uint64(t.ID))
+	buf = binary.AppendUvarint(buf, uint64(t.EventID))
+	buf = binary.AppendUvarint(buf, uint64(t.UserID))
+	buf = binary.AppendUvarint(buf, uint64(t.TypeID))
+	buf = binary.AppendUvarint(buf, uint64(t.ExpiresAt.Unix()))
+	return buf
+}
+
+func decode(payload []byte) (Ticket, error) {
+	if len(payload) == 0 || payload[0] != formatVersion {
+		return Ticket{}, errors.New("unsupported ticket version")
+	}
+
+	fields := make([]uint64, 5)
+	rest := payload[1:]
+	for i := range fields {
+		value, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return Ticket{}, errors.New("malformed ticket")
+		}
+		fields[i] = value
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return Ticket{}, errors.New("malformed ticket")
+	}
+
+	return Ticket{
+		ID:        uint(fields[0]),
+		EventID:   uint(fields[1]),
+		UserID:    uint(fields[2]),
+		TypeID:    uint(fields[3]),
+		ExpiresAt: time.Unix(int64(fields[4]), 0).UTC(),
+	}, nil
+}
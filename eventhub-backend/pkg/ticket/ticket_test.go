@@ -0,0 +1,167 @@
+package ticket
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newSigner(t *testing.T, kid string, key ed25519.PrivateKey, verifyKeys map[string]ed25519.PublicKey) *Signer {
+	t.Helper()
+	signer, err := NewSigner(kid, key, verifyKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func validTicket() Ticket {
+	return Ticket{
+		ID:        42,
+		EventID:   7,
+		UserID:    300,
+		TypeID:    5,
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+	}
+}
+
+// signRaw подписывает произвольную полезную нагрузку, минуя encode
+func signRaw(key ed25519.PrivateKey, payload []byte) string {
+	signed := append(append([]byte{}, payload...), ed25519.Sign(key, payload)...)
+	return base64.RawURLEncoding.EncodeToString(signed)
+}
+
+func expectError(t *testing.T, signer *Signer, token, want string) {
+	t.Helper()
+	_, err := signer.Verify(token)
+	if err == nil {
+		t.Fatalf("ожидалась ошибка %q, билет принят", want)
+	}
+	if err.Error() != want {
+		t.Fatalf("ожидалась ошибка %q, получено %q", want, err.Error())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	signer := newSigner(t, "k1", newKey(t), nil)
+
+	want := validTicket()
+	got, err := signer.Verify(signer.Sign(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("получен %+v, ожидался %+v", got, want)
+	}
+}
+
+func TestRotatedKey(t *testing.T) {
+	oldKey := newKey(t)
+	oldSigner := newSigner(t, "old", oldKey, nil)
+	token := oldSigner.Sign(validTicket())
+
+	// после ротации старый ключ остается только для проверки
+	signer := newSigner(t, "new", newKey(t), map[string]ed25519.PublicKey{
+		"old": oldKey.Public().(ed25519.PublicKey),
+	})
+	if _, err := signer.Verify(token); err != nil {
+		t.Fatalf("билет, подписанный старым ключом, не принят: %v", err)
+	}
+	if len(signer.Keys().Keys) != 2 {
+		t.Fatalf("в JWKS должно быть два ключа, получено %d", len(signer.Keys().Keys))
+	}
+}
+
+func TestTamperedPayload(t *testing.T) {
+	signer := newSigner(t, "k1", newKey(t), nil)
+
+	raw, err := base64.RawURLEncoding.DecodeString(signer.Sign(validTicket()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ID билета - второй байт после версии
+	raw[1]++
+
+	expectError(t, signer, base64.RawURLEncoding.EncodeToString(raw), "invalid signature")
+}
+
+func TestUnknownKey(t *testing.T) {
+	other := newSigner(t, "other", newKey(t), nil)
+	signer := newSigner(t, "k1", newKey(t), nil)
+
+	expectError(t, signer, other.Sign(validTicket()), "invalid signature")
+}
+
+func TestExpired(t *testing.T) {
+	signer := newSigner(t, "k1", newKey(t), nil)
+
+	expired := validTicket()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	expectError(t, signer, signer.Sign(expired), "ticket expired")
+}
+
+// срок в билете проверяет вызывающий, если мероприятие перенесли после выдачи
+func TestVerifySignatureIgnoresExpiry(t *testing.T) {
+	signer := newSigner(t, "k1", newKey(t), nil)
+
+	expired := validTicket()
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Truncate(time.Second).UTC()
+
+	got, err := signer.VerifySignature(signer.Sign(expired))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expired {
+		t.Fatalf("получено %+v, ожидалось %+v", got, expired)
+	}
+
+	other := newSigner(t, "k2", newKey(t), nil)
+	if _, err := signer.VerifySignature(other.Sign(expired)); err == nil || err.Error() != "invalid signature" {
+		t.Fatalf("чужая подпись: %v", err)
+	}
+}
+
+func TestWrongVersion(t *testing.T) {
+	key := newKey(t)
+	signer := newSigner(t, "k1", key, nil)
+
+	payload := encode(validTicket())
+	payload[0] = formatVersion + 1
+
+	expectError(t, signer, signRaw(key, payload), "unsupported ticket version")
+}
+
+func TestMalformed(t *testing.T) {
+	key := newKey(t)
+	signer := newSigner(t, "k1", key, nil)
+
+	payload := encode(validTicket())
+	tests := map[string]string{
+		"не base64":            "***",
+		"короче подписи":       base64.RawURLEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)),
+		"обрезанные поля":      signRaw(key, payload[:3]),
+		"лишние байты в конце": signRaw(key, append(payload, 0)),
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			expectError(t, signer, token, "malformed ticket")
+		})
+	}
+}
+
+func TestEmptyKID(t *testing.T) {
+	if _, err := NewSigner("", newKey(t), nil); err == nil {
+		t.Fatal("подписчик без идентификатора ключа создан")
+	}
+}
@@ -43,6 +43,17 @@ const formatDate = (date: Date | null) => {
   });
 };
 
+const combineDateTime = (date: Date | null, time: Date | null) => {
+  if (!date || !time) return null;
+  return new Date(
+    date.getFullYear(),
+    date.getMonth(),
+    date.getDate(),
+    time.getHours(),
+    time.getMinutes(),
+  );
+};
+
 const formatTime = (date: Date | null) => {
   if (!date) return "Выбрать время";
   return date.toLocaleTimeString("ru-RU", {
@@ -148,15 +159,12 @@ const ModalScreen = () => {
 
   const createEvent = async () => {
     try {
-      const formattedDate = tempEvent.date
-        ? tempEvent.date.toISOString()
-        : null;
-      const formattedStartTime = tempEvent.start_time
-        ? tempEvent.start_time.toTimeString().split(" ")[0]
-        : null;
-      const formattedEndTime = tempEvent.end_time
-        ? tempEvent.end_time.toTimeString().split(" ")[0]
-        : null;
+      const startsAt = combineDateTime(tempEvent.date, tempEvent.start_time);
+      const endsAt = combineDateTime(tempEvent.date, tempEvent.end_time);
+      // окончание раньше начала - мероприятие заканчивается на следующий день
+      if (startsAt && endsAt && endsAt <= startsAt) {
+        endsAt.setDate(endsAt.getDate() + 1);
+      }
 
       const response = (await fetchWithToken(
         "http://" + ADDRESS + "/api/organizations/" + org_id + "/events",
@@ -168,9 +176,9 @@ const ModalScreen = () => {
             category: tempEvent.category,
             is_public: tempEvent.is_public,
             location: tempEvent.location,
-            date: formattedDate,
-            start_time: formattedStartTime,
-            end_time: formattedEndTime,
+            starts_at: startsAt?.toISOString(),
+            ends_at: endsAt?.toISOString(),
+            timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
           }),
         },
       )) as Response;
@@ -40,7 +40,8 @@ type Event = {
 type EventResponse = {
   event: Event;
   is_joined: boolean;
-  is_creator: boolean;
+  is_host: boolean;
+  role: string;
 };
 
 type Participant = {
@@ -83,7 +84,7 @@ const ModalScreen = () => {
   });
 
   const [userJoined, setUserJoined] = useState<boolean>(false);
-  const [isCreator, setIsCreator] = useState<boolean>(false);
+  const [isHost, setIsHost] = useState<boolean>(false);
   const [participants, setParticipants] = useState<Participant[]>([]);
   const [creator, setCreator] = useState<Participant>({
     id: 0,
@@ -115,7 +116,7 @@ const ModalScreen = () => {
       const data = (await response.json()) as EventResponse;
       setEvent(data.event);
       setUserJoined(data.is_joined);
-      setIsCreator(data.is_creator);
+      setIsHost(data.is_host);
 
       // Now we have the event data, we can load the creator
       if (data.event.creator_id) {
@@ -328,7 +329,7 @@ const ModalScreen = () => {
         <View style={styles.content}>
           <View style={styles.tags}>
             {isCompleted == "false" ? (
-              isCreator ? (
+              isHost ? (
                 <View style={styles.creator}>
                   <Text style={styles.creator__text}>ВЫ СОЗДАТЕЛЬ</Text>
                 </View>
@@ -416,7 +417,7 @@ const ModalScreen = () => {
 
       {/* Нижние кнопки */}
       {isCompleted == "false" ? (
-        isCreator ? (
+        isHost ? (
           <View style={styles.edit_buttons}>
             <View style={{ flexGrow: 3 }}>
               <CustomButton
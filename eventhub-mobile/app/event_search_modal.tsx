@@ -1,7 +1,8 @@
 import EventCard from "@/components/EventCard";
-import { ADDRESS_NO_PORT } from "@/constants/address";
+import { ADDRESS } from "@/constants/address";
 import { colors } from "@/constants/colors";
 import { fonts } from "@/constants/fonts";
+import { fetchWithToken } from "@/utils/tokenInterceptor";
 import { format, parseISO } from "date-fns";
 import { ru } from "date-fns/locale";
 import { router } from "expo-router";
@@ -17,9 +18,22 @@ import {
   View,
 } from "react-native";
 
+type SearchHit = {
+  event: {
+    id: number;
+    title: string;
+    category: string;
+    date: string;
+    start_time: string;
+    end_time: string;
+    location: string;
+    status: string;
+  };
+};
+
 const ModalScreen = () => {
   const [query, setQuery] = useState("");
-  const [results, setResults] = useState([]);
+  const [results, setResults] = useState<SearchHit[]>([]);
 
   const searchbarRef = useRef<TextInput>(null);
 
@@ -45,27 +59,22 @@ const ModalScreen = () => {
 
   const search = useCallback(async (text: string) => {
     try {
-      const res = await fetch(`http://${ADDRESS_NO_PORT}:9200/events/_search`, {
-        method: "POST",
-        headers: {
-          "Content-Type": "application/json",
+      const res = (await fetchWithToken(
+        "http://" + ADDRESS + "/api/events/search?q=" + encodeURIComponent(text),
+        {
+          method: "GET",
         },
-        body: JSON.stringify({
-          query: {
-            multi_match: {
-              query: text,
-              type: "best_fields",
-              fields: ["title^3", "category^2", "description"],
-              fuzziness: "AUTO",
-              operator: "and",
-            },
-          },
-        }),
-      });
+      )) as Response;
+
+      if (!res.ok) {
+        if (res.status === 401) {
+          router.replace("/(auth)/login");
+        }
+        throw new Error(`Ошибка запроса: ${res.status}`);
+      }
 
       const data = await res.json();
-      const hits = data.hits?.hits || [];
-      setResults(hits);
+      setResults(data.events || []);
     } catch (err) {
       console.error("Ошибка поиска:", err);
     }
@@ -97,18 +106,18 @@ const ModalScreen = () => {
         </View>
 
         <ScrollView style={styles.results}>
-          {results.map((event) => {
+          {results.map(({ event }) => {
             return (
-              <View key={event._source.id} style={{ marginBottom: 12 }}>
+              <View key={event.id} style={{ marginBottom: 12 }}>
                 <EventCard
-                  id={event._source.id}
-                  title={event._source.title}
-                  category={event._source.category}
-                  date={formatDate(event._source.date)}
-                  start_time={formatTime(event._source.start_time)}
-                  end_time={formatTime(event._source.end_time)}
-                  location={event._source.location}
-                  isCompleted={event._source.is_completed}
+                  id={event.id}
+                  title={event.title}
+                  category={event.category}
+                  date={formatDate(event.date)}
+                  start_time={formatTime(event.start_time)}
+                  end_time={formatTime(event.end_time)}
+                  location={event.location}
+                  isCompleted={event.status === "completed"}
                 />
               </View>
             );
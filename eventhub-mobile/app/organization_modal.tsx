@@ -114,7 +114,11 @@ const ModalScreen = () => {
       }
 
       const data = await response.json();
-      setActiveEvents(data.active);
+      setActiveEvents([
+        ...(data.ongoing ?? []),
+        ...(data.active ?? []),
+        ...(data.postponed ?? []),
+      ]);
       setCompletedEvents(data.completed);
     } catch (error) {
       Alert.alert("Ошибка сервера", "Проверьте подключение.");
@@ -66,7 +66,10 @@ const RenderCategoryFull = ({
   emptyInfo,
 }: RenderCategoryProps) => {
   const activeEvents = events.filter(
-    (event: Event) => event.status == "active",
+    (event: Event) =>
+      event.status == "active" ||
+      event.status == "ongoing" ||
+      event.status == "postponed",
   );
   const completedEvents = events.filter(
     (event: Event) => event.status == "completed",